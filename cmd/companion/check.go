@@ -0,0 +1,55 @@
+package main
+
+import (
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/tts"
+	"OpenAIClient/internal/service/tts/normalize"
+	_ "OpenAIClient/internal/service/tts/providers" // регистрация TTS-провайдеров
+	"errors"
+	"fmt"
+)
+
+// configCheck печатает итоговый конфиг (секреты скрыты) и все найденные проблемы.
+// Возвращает код выхода: 0 — конфиг в порядке, 1 — есть проблемы.
+func configCheck(file config.File) int {
+	cfg, err := config.NewConfigFrom(file)
+
+	fmt.Println("Effective config:")
+	for _, line := range cfg.Effective() {
+		fmt.Println("  " + line)
+	}
+
+	var problems []string
+	var ve *config.ValidationError
+	if errors.As(err, &ve) {
+		problems = append(problems, ve.Problems...)
+	}
+	if errors.As(validate(cfg), &ve) {
+		problems = append(problems, ve.Problems...)
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("\nConfig OK")
+		return 0
+	}
+	fmt.Printf("\nProblems (%d):\n", len(problems))
+	for _, p := range problems {
+		fmt.Println("  - " + p)
+	}
+	return 1
+}
+
+// validate проверяет конфиг, настройки TTS-провайдеров цепочки (их проверки живут в реестре tts) и словарь произношения одним списком проблем.
+func validate(cfg *config.Config) error {
+	all := &config.ValidationError{}
+	for _, err := range []error{cfg.Validate(), tts.Validate(cfg), normalize.Validate(cfg.TTSNormalize)} {
+		var ve *config.ValidationError
+		if errors.As(err, &ve) {
+			all.Problems = append(all.Problems, ve.Problems...)
+		}
+	}
+	if len(all.Problems) == 0 {
+		return nil
+	}
+	return all
+}
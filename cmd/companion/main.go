@@ -4,21 +4,32 @@ import (
 	chatadapter "OpenAIClient/internal/adapter/chat/twitch"
 	"OpenAIClient/internal/adapter/conversation"
 	"OpenAIClient/internal/adapter/message"
+	"OpenAIClient/internal/app/audit"
+	"OpenAIClient/internal/app/control"
+	"OpenAIClient/internal/app/dashboard"
+	"OpenAIClient/internal/app/overlay"
+	"OpenAIClient/internal/app/reloader"
 	"OpenAIClient/internal/app/requester"
 	"OpenAIClient/internal/app/scheduler"
 	"OpenAIClient/internal/app/screenshotter"
+	"OpenAIClient/internal/app/supervisor"
 	"OpenAIClient/internal/app/trial"
 	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/bus"
 	chatsvc "OpenAIClient/internal/service/chat"
 	"OpenAIClient/internal/service/companion"
 	"OpenAIClient/internal/service/events/dota"
+	"OpenAIClient/internal/service/health"
+	"OpenAIClient/internal/service/metrics"
 	"OpenAIClient/internal/service/notify"
 	"OpenAIClient/internal/service/speech"
 	statebuf "OpenAIClient/internal/service/state"
 	"OpenAIClient/internal/service/stt/handy"
+	"OpenAIClient/internal/service/tts/player"
 	"OpenAIClient/internal/service/vtube"
 	"context"
 	"errors"
+	"flag"
 	"os"
 	"os/signal"
 	"strings"
@@ -29,9 +40,29 @@ import (
 )
 
 func main() {
+	// companion config check — проверить конфиг и вывести итоговые значения без запуска
+	args := os.Args[1:]
+	check := len(args) > 1 && args[0] == "config" && args[1] == "check"
+	if check {
+		args = args[2:]
+	}
+	var file config.File
+	flag.StringVar(&file.Path, "config", "", "Файл конфига YAML (по умолчанию "+config.DefaultFile+", если есть)")
+	flag.StringVar(&file.Profile, "profile", "", "Профиль из секции profiles файла конфига, напр. wows-stream")
+	_ = flag.CommandLine.Parse(args)
+	if check {
+		os.Exit(configCheck(file))
+	}
+
+	// Код выхода ставится по ходу main; os.Exit — последним, после всех отложенных закрытий
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
 	defer holdConsoleIfNeeded()
 
-	cfg := config.NewConfig()
 	// создаём предустановленный регистратор zap
 	logger, err := zap.NewDevelopment(zap.WithFatalHook(zapcore.WriteThenGoexit))
 	if err != nil {
@@ -47,8 +78,32 @@ func main() {
 		}
 	}()
 
+	// Конфиг: при любых проблемах перечисляем их все и не запускаемся
+	cfg, err := config.NewConfigFrom(file)
+	if err == nil {
+		err = validate(cfg)
+	}
+	if err != nil {
+		var ve *config.ValidationError
+		if errors.As(err, &ve) {
+			for _, p := range ve.Problems {
+				sugar.Errorw("Invalid config", "problem", p)
+			}
+		} else {
+			sugar.Errorw("Invalid config", "error", err)
+		}
+		sugar.Info("Check settings: companion config check")
+		exitCode = 1 // отказ от запуска отличим от штатной остановки, как у config check
+		return
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
+	// После первого Ctrl+C возвращаем обработку сигнала по умолчанию: повторный Ctrl+C завершит процесс сразу
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 	// клиента OpenAI (использует переменные окружения OPENAI_API_KEY)
 	oClient := openai.NewClient()
 
@@ -60,6 +115,22 @@ func main() {
 		"DebugMode", cfg.DebugMode,
 	)
 
+	// Шина внутренних событий (тики, промпты, TTS, VTube) для подписчиков Control API
+	events := bus.New()
+	// Реестр здоровья подсистем для веб-панели; состояния компонентов ведёт супервизор
+	reg := health.New()
+	// Супервизор: запуск по порядку регистрации, перезапуск упавших с backoff, остановка в обратном порядке
+	sup := supervisor.New(supervisor.Config{StopTimeout: cfg.ShutdownTimeout}, reg, sugar)
+
+	// Журнал тиков (JSONL) — подписываемся до старта планировщика и останавливаемся последними
+	if cfg.Audit.Enabled {
+		al := audit.New(cfg.Audit, message.Model, events, sugar)
+		sup.Add(supervisor.Component{Name: "audit", Run: func(ctx context.Context) error {
+			al.Run(ctx)
+			return nil
+		}})
+	}
+
 	convAdapter := conversation.New(&oClient, sugar)
 	msgAdapter := message.New(&oClient, sugar)
 	comp := companion.NewCompanion(convAdapter, msgAdapter)
@@ -73,77 +144,144 @@ func main() {
 	// State — буфер сообщений игрового состояния
 	st := statebuf.New(cfg.StateMax)
 
-	// STT Handy listener — фоновый запуск
-	stt := handy.New(handy.Config{HandyWindow: cfg.STTHandyWindow, HotkeyDelay: cfg.STTHotkeyDelay})
-	go func() {
-		if err := stt.Run(ctx); err != nil {
-			if errors.Is(err, context.Canceled) {
-				sugar.Infow("STT service stopped", "reason", "context canceled")
-			} else {
-				sugar.Errorw("STT service stopped", "error", err)
+	// STT Handy listener: при перезапуске создаём сервис заново (канал событий закрывается вместе с Run)
+	sup.Add(supervisor.Component{Name: "stt", Restart: supervisor.RestartOnFailure, Run: func(ctx context.Context) error {
+		stt := handy.New(handy.Config{HandyWindow: cfg.STTHandyWindow, HotkeyDelay: cfg.STTHotkeyDelay})
+		fwdCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		// Подписка на события STT: обрабатываем только финальный текст от Handy
+		go func() {
+			for {
+				select {
+				case <-fwdCtx.Done():
+					return
+				case ev, ok := <-stt.Events():
+					if !ok {
+						return
+					}
+					if ev.Type == handy.EventHandyFinalText {
+						sp.Add(ev.Text)
+					}
+				}
 			}
-		}
-	}()
+		}()
+		return stt.Run(ctx)
+	}})
 
-	// StateServer (Dota GSI) — запуск в отдельной горутине при включённой конфигурации
+	// StateServer (Dota GSI) — при включённой конфигурации
 	if cfg.StateServer.Enabled {
 		dotaSrv := dota.NewDotaStateServer(cfg.StateServer, st, sugar)
-		if err := dotaSrv.Start(ctx); err != nil {
-			sugar.Errorw("failed to start DotaStateServer", "error", err)
-		} else {
-			sugar.Infow("DotaStateServer started", "addr", cfg.StateServer.BindAddr, "path", cfg.StateServer.Path)
-		}
+		sup.Add(supervisor.Component{Name: "dota", Restart: supervisor.RestartOnFailure, Run: dotaSrv.Run})
+	} else {
+		reg.Set("dota", health.StateDisabled, "")
 	}
-	// Подписка на события STT
-	go func() {
-		for ev := range stt.Events() {
-			// Обрабатываем только финальный текст от Handy
-			if ev.Type != handy.EventHandyFinalText {
-				continue
-			}
-			sp.Add(ev.Text)
-		}
-	}()
+
+	// Общий аудиовыход: речь и уведомления смешиваются в одном микшере
+	// Выходы: колонки, файлы клипов, запись сессии (AUDIO_SINKS)
+	mixer := player.NewMixer(player.MixerConfig{
+		SampleRate: cfg.AudioSampleRate,
+		DuckDB:     cfg.AudioDuckDB,
+		Sinks:      cfg.AudioSinks,
+		RecordDir:  cfg.AudioRecordDir,
+		Loudness:   player.Loudness(cfg.AudioLoudness),
+		Logger:     sugar,
+	})
+	defer mixer.Close()
 
 	// Нотификатор звука — пути берём из конфига (env/флаг), конструктор сам найдёт дефолты, если пусто
-	notifier := notify.NewSoundNotifier(sugar, cfg.NotificationSendAI, cfg.NotificationSendTTS)
-	// Запуск Twitch IRC слушателя фоновой горутиной (если конфигурация задана)
-	go func() {
-		_ = chatadapter.Run(ctx, sugar, chatadapter.Config{
-			Username: cfg.TwitchUsername,
-			OAuth:    cfg.TwitchOAuthToken,
-			Channel:  cfg.TwitchChannel,
-		}, ch)
-	}()
+	notifier := notify.NewSoundNotifier(sugar, mixer, cfg.NotificationSendAI, cfg.NotificationSendTTS)
+	// Twitch IRC слушатель (если конфигурация задана); обрыв соединения — перезапуск с backoff
+	if cfg.TwitchUsername != "" && cfg.TwitchOAuthToken != "" && cfg.TwitchChannel != "" {
+		sup.Add(supervisor.Component{Name: "twitch", Restart: supervisor.RestartOnFailure, Run: func(ctx context.Context) error {
+			return chatadapter.Run(ctx, sugar, chatadapter.Config{
+				Username: cfg.TwitchUsername,
+				OAuth:    cfg.TwitchOAuthToken,
+				Channel:  cfg.TwitchChannel,
+			}, ch)
+		}})
+	} else {
+		reg.Set("twitch", health.StateDisabled, "")
+	}
 
-	req := requester.New(cfg, comp, sp, st, ch, notifier, sugar)
-	// запускаем скриншоттер в отдельной горутине, если включён в конфиге
+	req := requester.New(cfg, comp, sp, st, ch, notifier, events, sugar)
+	// скриншоттер, если включён в конфиге
 	if cfg.ScreenshotEnabled {
 		scr := screenshotter.New(cfg, sugar)
-		go scr.Run(ctx)
+		sup.Add(supervisor.Component{Name: "screenshotter", Run: func(ctx context.Context) error {
+			scr.Run(ctx)
+			return nil
+		}})
 	} else {
 		sugar.Infow("Screenshotter is disabled by config; not starting")
+		reg.Set("screenshotter", health.StateDisabled, "")
 	}
 	// VTube Studio клиент — при включении в конфиге
 	var vts *vtube.Client
 	if cfg.VTube.Enabled && strings.TrimSpace(cfg.VTubeAPIKey) != "" {
 		vts = vtube.New(cfg.VTube, cfg.VTubeAPIKey, sugar)
-		if err := vts.Start(ctx); err != nil {
-			// Требование: при невозможности подключиться к VTube — залогировать и завершить приложение
-			sugar.Fatalw("Failed to start VTube client", "error", err)
-			return
+		// Загрузка хоткеев; пока VTube недоступен — повторяем с backoff, тики идут без эмоций
+		sup.Add(supervisor.Component{Name: "vtube", Restart: supervisor.RestartOnFailure, Run: func(ctx context.Context) error {
+			if err := vts.Start(ctx); err != nil {
+				return err
+			}
+			sugar.Infow("VTube client started")
+			<-ctx.Done()
+			return nil
+		}})
+		if cfg.VTube.LipSync.Enabled {
+			// Липсинк по звучащей речи: свой постоянный канал к VTS, независимый от хоткеев
+			sup.Add(supervisor.Component{Name: "lipsync", Restart: supervisor.RestartOnFailure,
+				Run: vtube.NewLipSync(vts, mixer, cfg.VTube.LipSync, sugar).Run})
 		}
-		sugar.Infow("VTube client started")
 	} else {
 		sugar.Infow("VTube client disabled or no API key provided")
+		reg.Set("vtube", health.StateDisabled, "")
+	}
+
+	sch := scheduler.New(cfg, &oClient, req, sp, mixer, events, sugar, vts)
+
+	// Горячая перезагрузка промптов и темпа тиков: по изменению .env и по команде Control API
+	rl := reloader.New(".env", cfg, validate, events, sugar, req, sch)
+	if cfg.ConfigWatch {
+		sup.Add(supervisor.Component{Name: "config-watch", Restart: supervisor.RestartOnFailure, Run: rl.Run})
 	}
 
-	sch := scheduler.New(cfg, req, sp, sugar, vts)
-	if err := sch.Run(ctx); err != nil {
-		if errors.Is(err, context.Canceled) {
-			sugar.Infow("Scheduler stopped", "reason", "context canceled")
-			return
+	// Control API — локальное HTTP управление (Stream Deck, скрипты)
+	if cfg.ControlAPI.Enabled {
+		ctl := control.New(cfg.ControlAPI, sch, sp, ch, st, events, sugar)
+		// Веб-панель оператора на том же сервере
+		if cfg.ControlAPI.Dashboard {
+			dash := dashboard.New(cfg.ControlAPI.DashboardTicks, events, reg, sp, ch, st, sugar)
+			sup.Add(supervisor.Component{Name: "dashboard", Run: func(ctx context.Context) error {
+				dash.Run(ctx)
+				return nil
+			}})
+			dash.Register(ctl)
 		}
-		sugar.Fatalw("Scheduler stopped with error", "error", err)
+		// Метрики Prometheus (с той же авторизацией, что и API)
+		if cfg.ControlAPI.Metrics {
+			ctl.Handle("GET /metrics", metrics.Handler())
+		}
+		// Оверлей субтитров для OBS
+		if cfg.ControlAPI.Overlay {
+			overlay.New(cfg.ControlAPI.OverlayCSS, sugar).Register(ctl)
+		}
+		rl.Register(ctl)
+		sup.Add(supervisor.Component{Name: "control", Run: ctl.Run})
+	}
+
+	// Планировщик стартует последним и останавливается первым: даём договорить текущей речи
+	sup.Add(supervisor.Component{
+		Name:        "scheduler",
+		Run:         sch.Run,
+		Critical:    true,
+		StopTimeout: cfg.ShutdownDrain + cfg.ShutdownTimeout,
+	})
+
+	if err := sup.Run(ctx); err != nil {
+		sugar.Errorw("Companion stopped with error", "error", err)
+		exitCode = 1 // после отложенных закрытий (микшер, логгер)
+		return
 	}
+	sugar.Infow("Companion stopped")
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"OpenAIClient/internal/service/bus"
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/gorilla/websocket"
+)
+
+// Тестовый клиент потока событий Control API: печатает события компаньона в консоль.
+// Пример запуска:
+//
+//	go run ./cmd/events-client -addr 127.0.0.1:3100 -mode ws -token SECRET
+func main() {
+	var (
+		addr  string
+		mode  string
+		token string
+		raw   bool
+	)
+	flag.StringVar(&addr, "addr", "127.0.0.1:3100", "Адрес Control API")
+	flag.StringVar(&mode, "mode", "ws", "Транспорт: ws|sse")
+	flag.StringVar(&token, "token", os.Getenv("CONTROL_API_AUTH_TOKEN"), "Токен Control API (по умолчанию из CONTROL_API_AUTH_TOKEN)")
+	flag.BoolVar(&raw, "raw", false, "Печатать сырой JSON событий")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	show := func(data []byte) {
+		if raw {
+			log.Println(string(data))
+			return
+		}
+		var ev struct {
+			bus.Event
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &ev); err != nil {
+			log.Printf("bad event: %v: %s", err, data)
+			return
+		}
+		log.Printf("#%d tick=%d %-18s %s", ev.Seq, ev.TickID, ev.Type, ev.Data)
+	}
+
+	var err error
+	switch strings.ToLower(mode) {
+	case "sse":
+		err = readSSE(ctx, "http://"+addr+"/api/events", token, show)
+	default:
+		err = readWS(ctx, "ws://"+addr+"/api/events/ws", token, show)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Fatalf("stream error: %v", err)
+	}
+}
+
+// readWS читает события из WebSocket до отмены контекста.
+func readWS(ctx context.Context, u, token string, handle func([]byte)) error {
+	h := http.Header{}
+	if token != "" {
+		h.Set("Authorization", "Bearer "+token)
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u, h)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	log.Printf("connected to %s", u)
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		handle(data)
+	}
+}
+
+// readSSE читает события из потока Server-Sent Events до отмены контекста.
+func readSSE(ctx context.Context, u, token string, handle func([]byte)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.URL.RawQuery = url.Values{"token": {token}}.Encode()
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	log.Printf("connected to %s", u)
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for sc.Scan() {
+		if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+			handle([]byte(data))
+		}
+	}
+	return sc.Err()
+}
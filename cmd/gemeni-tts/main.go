@@ -32,7 +32,11 @@ func main() {
 	)
 
 	// Базовая конфигурация приложения (подтягивает .env и ENV)
-	cfg := config.NewConfig()
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	// Дефолтный текст для проверки
 	flag.StringVar(&text, "text", "Это тестовый запрос к сервису Gemini TTS. Проверка связи и синтеза речи.", "Тестовый текст для синтеза речи")
@@ -74,18 +78,18 @@ func main() {
 	logger := zl.Sugar()
 	defer zl.Sync() // flush
 
-	p := player.New() // громкость регулируется на стороне провайдера
-	client := gtts.New(logger)
+	p := player.NewMixer(player.MixerConfig{SampleRate: cfg.AudioSampleRate}) // громкость регулируется на стороне провайдера
+	client := gtts.New(cfg.GeminiTTS, logger)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	format, rc, err := client.Synthesize(ctx, text, cfg.GeminiTTS.Prompt, cfg.GeminiTTS)
+	format, rc, err := client.Synthesize(ctx, text, cfg.GeminiTTS.Prompt)
 	if err != nil {
 		logger.Errorw("Gemini TTS synthesize failed", "error", err)
 		os.Exit(1)
 	}
-	if err := p.Play(format, rc); err != nil {
+	if err := p.Play(context.Background(), format, rc, player.Options{}); err != nil {
 		logger.Errorw("Gemini TTS play failed", "error", err)
 		os.Exit(1)
 	}
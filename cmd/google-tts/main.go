@@ -16,7 +16,11 @@ import (
 // Небольшая утилита: делает GET к Google TTS Voices и печатает список голосов для ru-RU.
 // Конфигурация берётся из internal/config (в частности путь к cred-файлу сервисного аккаунта).
 func main() {
-	cfg := config.NewConfig()
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	// Установим GOOGLE_APPLICATION_CREDENTIALS из конфига, если не задано в окружении.
 	if os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") == "" && cfg.GoogleTTS.CredentialsPath != "" {
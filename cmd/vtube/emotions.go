@@ -21,7 +21,10 @@ import (
 // 4) Случайно выбираем 1..3 тегов, логируем и отправляем TriggerByNames.
 // 5) Ждём короткую паузу и отправляем TriggerReset.
 func runEmotionsTest(ctx context.Context) error {
-	cfg := config.NewConfig()
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
 	if !cfg.VTube.Enabled {
 		return errors.New("VTube в конфиге отключён (VTUBE_ENABLED=false)")
 	}
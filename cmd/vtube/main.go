@@ -75,7 +75,10 @@ func main() {
 		return
 	}
 
-	cfg := config.NewConfig()
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
 	if cfg.VTubeAPIKey == "" {
 		log.Fatal("переменная окружения VTUBE_API_KEY не задана; получите токен через vtube-auth (старую версию) и пропишите его в окружении")
 	}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/tts/player"
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -10,12 +12,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
-
-	"github.com/faiface/beep"
-	"github.com/faiface/beep/mp3"
-	"github.com/faiface/beep/speaker"
-	"github.com/faiface/beep/wav"
 )
 
 // Утилита для синтеза речи через Yandex SpeechKit TTS.
@@ -32,7 +28,11 @@ func main() {
 		emotion string
 	)
 
-	cfg := config.NewConfig()
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	// Немного разумных дефолтов
 	flag.StringVar(&text, "text", "Капитан, мы потерпели поражение, противники в очках взяли верх. Ты командовал \"Mogami\", стрельба из главного калибра была знатной", "Текст для синтеза речи")
@@ -40,7 +40,7 @@ func main() {
 	flag.StringVar(&emotion, "emotion", "neutral", "Эмоция (например: neutral, good)")
 	flag.StringVar(&format, "format", "mp3", "Формат выходного аудио (oggopus|mp3|wav)")
 	flag.StringVar(&out, "out", "speech.ogg", "Имя выходного файла (в текущем каталоге)")
-	flag.BoolVar(&play, "play", true, "Сразу воспроизвести результат без сохранения файла (поддерживаются wav, mp3)")
+	flag.BoolVar(&play, "play", true, "Сразу воспроизвести результат без сохранения файла")
 	flag.Parse()
 
 	apiKey := cfg.YandexTTS.APIKey
@@ -90,27 +90,13 @@ func main() {
 
 	// Если запрошено немедленное воспроизведение — играем напрямую из ответа (без файлов)
 	if play {
-		// Ограничения: oggopus (Opus) напрямую не поддержан в beep.
-		// Поддержим wav и mp3. Для oggopus подскажем выбрать другой формат.
-		switch strings.ToLower(format) {
-		case "wav":
-			if err := playWAV(resp.Body); err != nil {
-				fmt.Println("Не удалось воспроизвести WAV:", err)
-				os.Exit(1)
-			}
-			fmt.Println("Воспроизведение завершено.")
-			return
-		case "mp3":
-			if err := playMP3(resp.Body); err != nil {
-				fmt.Println("Не удалось воспроизвести MP3:", err)
-				os.Exit(1)
-			}
-			fmt.Println("Воспроизведение завершено.")
-			return
-		default:
-			fmt.Println("Формат '", format, "' не поддерживается для прямого воспроизведения. Укажите -format wav или mp3, либо не используйте -play.")
+		ply := player.NewMixer(player.MixerConfig{SampleRate: cfg.AudioSampleRate})
+		if err := ply.Play(context.Background(), format, resp.Body, player.Options{Kind: player.KindSpeech}); err != nil {
+			fmt.Println("Не удалось воспроизвести аудио:", err)
 			os.Exit(1)
 		}
+		fmt.Println("Воспроизведение завершено.")
+		return
 	}
 
 	// Определим фактическое имя выходного файла в текущем каталоге
@@ -154,37 +140,3 @@ func main() {
 
 	fmt.Printf("Готово. Аудио сохранено в: %s\n", outPath)
 }
-
-// Воспроизведение WAV из r (без сохранения в файл)
-func playWAV(r io.ReadCloser) error {
-	streamer, format, err := wav.Decode(r)
-	if err != nil {
-		return err
-	}
-	defer streamer.Close()
-
-	if err := speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10)); err != nil {
-		return err
-	}
-	done := make(chan struct{})
-	speaker.Play(beep.Seq(streamer, beep.Callback(func() { close(done) })))
-	<-done
-	return nil
-}
-
-// Воспроизведение MP3 из r (без сохранения в файл)
-func playMP3(r io.ReadCloser) error {
-	streamer, format, err := mp3.Decode(r)
-	if err != nil {
-		return err
-	}
-	defer streamer.Close()
-
-	if err := speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10)); err != nil {
-		return err
-	}
-	done := make(chan struct{})
-	speaker.Play(beep.Seq(streamer, beep.Callback(func() { close(done) })))
-	<-done
-	return nil
-}
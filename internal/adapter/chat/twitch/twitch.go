@@ -2,6 +2,7 @@ package twitch
 
 import (
 	svcchat "OpenAIClient/internal/service/chat"
+	"OpenAIClient/internal/service/metrics"
 	"context"
 	"regexp"
 	"strings"
@@ -57,12 +58,14 @@ func Run(ctx context.Context, logger *zap.SugaredLogger, cfg Config, chatSvc *sv
 		user := strings.TrimSpace(msg.User.Name)
 		text := strings.TrimSpace(msg.Message)
 		if text == "" || user == "" {
+			metrics.TwitchMessages.WithLabelValues("dropped", "empty").Inc()
 			return
 		}
 		// Вырезаем URL
 		text = urlRe.ReplaceAllString(text, "")
 		text = strings.TrimSpace(text)
 		if text == "" { // всё было URL — пропускаем
+			metrics.TwitchMessages.WithLabelValues("dropped", "url_only").Inc()
 			return
 		}
 
@@ -80,6 +83,7 @@ func Run(ctx context.Context, logger *zap.SugaredLogger, cfg Config, chatSvc *sv
 		}
 		mu.Unlock()
 		if drop {
+			metrics.TwitchMessages.WithLabelValues("dropped", "spam").Inc()
 			return
 		}
 
@@ -87,6 +91,7 @@ func Run(ctx context.Context, logger *zap.SugaredLogger, cfg Config, chatSvc *sv
 		ts := now.Format("15:04:05")
 		line := ts + " " + user + ": " + text
 		chatSvc.Add(line)
+		metrics.TwitchMessages.WithLabelValues("accepted", "").Inc()
 	})
 
 	errCh := make(chan error, 1)
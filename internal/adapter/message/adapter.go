@@ -14,6 +14,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// Model — модель OpenAI для запросов компаньона (пишется и в аудит тиков).
+const Model = openai.ChatModelGPT5_1
+
 type Adapter struct {
 	client *openai.Client
 	logger *zap.SugaredLogger
@@ -79,7 +82,7 @@ func (a *Adapter) SendTextWithImage(ctx context.Context, systemPrompt string, as
 	)
 
 	params := responses.ResponseNewParams{
-		Model: openai.ChatModelGPT5_1,
+		Model: Model,
 		Input: responses.ResponseNewParamsInputUnion{OfInputItemList: inputItems},
 	}
 
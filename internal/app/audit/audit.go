@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/bus"
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RecordVersion — версия схемы записи журнала; меняется только при несовместимых изменениях.
+const RecordVersion = 1
+
+// Record — одна строка журнала: всё, что нужно replay и оценке, об одном тике.
+type Record struct {
+	V          int                   `json:"v"`
+	TickID     int64                 `json:"tick_id"`
+	StartedAt  time.Time             `json:"started_at"`
+	DurationMs int64                 `json:"duration_ms"`
+	Trigger    string                `json:"trigger"` // timer|early|forced|say|barge-in
+	Status     string                `json:"status"`  // ok|empty|interrupted|error
+	Persona    string                `json:"persona"`
+	Character  *config.CharacterItem `json:"character,omitempty"`
+
+	Speech     int      `json:"speech"`
+	Chat       int      `json:"chat"`
+	State      int      `json:"state"`
+	Images     int      `json:"images"`
+	ImagePaths []string `json:"image_paths,omitempty"`
+
+	// Размеры промптов в символах и сами промпты — для воспроизведения запроса
+	SystemChars     int    `json:"system_chars"`
+	AssistantChars  int    `json:"assistant_chars"`
+	UserChars       int    `json:"user_chars"`
+	SystemPrompt    string `json:"system_prompt,omitempty"`
+	AssistantPrompt string `json:"assistant_prompt,omitempty"`
+	UserPrompt      string `json:"user_prompt,omitempty"`
+
+	Model    string `json:"model"`
+	Response string `json:"response,omitempty"`
+	LLMMs    int64  `json:"llm_ms,omitempty"`
+	Spoken   string `json:"spoken,omitempty"` // текст, отданный в TTS (для say совпадает с запросом оператора)
+
+	TTSProvider string   `json:"tts_provider,omitempty"`
+	SynthMs     int64    `json:"synth_ms,omitempty"`
+	AudioMs     int64    `json:"audio_ms,omitempty"` // сумма длительностей клипов всех кусков; 0 — неизвестна
+	PlaybackMs  int64    `json:"playback_ms,omitempty"`
+	Interrupted bool     `json:"interrupted,omitempty"`
+	VTubeTags   []string `json:"vtube_tags,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// Logger пишет журнал тиков по событиям шины.
+type Logger struct {
+	model  string
+	logger *zap.SugaredLogger
+	out    *rotator
+
+	events      <-chan bus.Event
+	unsubscribe func()
+	open        map[int64]*Record // незавершённые тики
+}
+
+// New создаёт журнал и сразу подписывается на шину. model — модель ИИ для поля model.
+// Подписка без потерь: пока диск тормозит, события копятся в очереди, и записи тиков остаются полными.
+func New(cfg config.AuditConfig, model string, events *bus.Bus, logger *zap.SugaredLogger) *Logger {
+	sub, unsubscribe := events.SubscribeLossless()
+	return &Logger{
+		model:       model,
+		logger:      logger,
+		out:         newRotator(cfg.Dir, int64(cfg.MaxSizeMB)<<20),
+		events:      sub,
+		unsubscribe: unsubscribe,
+		open:        map[int64]*Record{},
+	}
+}
+
+// Run пишет записи до отмены контекста, затем закрывает файл.
+func (l *Logger) Run(ctx context.Context) {
+	defer func() {
+		l.unsubscribe()
+		_ = l.out.Close()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-l.events:
+			if !ok {
+				return
+			}
+			if rec := l.apply(ev); rec != nil {
+				l.write(rec)
+			}
+		}
+	}
+}
+
+// apply дополняет запись тика; возвращает запись, когда тик завершён.
+func (l *Logger) apply(ev bus.Event) *Record {
+	if ev.TickID == 0 {
+		return nil
+	}
+	if data, ok := ev.Data.(bus.TickStart); ok {
+		l.open[ev.TickID] = &Record{
+			V: RecordVersion, TickID: ev.TickID, StartedAt: ev.At, Model: l.model,
+			Trigger: data.Trigger, Persona: data.Persona, Character: data.Character,
+		}
+		return nil
+	}
+	rec := l.open[ev.TickID]
+	if rec == nil {
+		return nil // начало тика потеряно (подписка позже старта или дроп)
+	}
+	switch data := ev.Data.(type) {
+	case bus.PromptSent:
+		rec.Speech, rec.Chat, rec.State, rec.Images = data.Speech, data.Chat, data.State, data.Images
+		rec.ImagePaths = data.ImagePaths
+		rec.SystemPrompt, rec.AssistantPrompt, rec.UserPrompt = data.SystemPrompt, data.AssistantPrompt, data.UserPrompt
+		rec.SystemChars = len([]rune(data.SystemPrompt))
+		rec.AssistantChars = len([]rune(data.AssistantPrompt))
+		rec.UserChars = len([]rune(data.UserPrompt))
+	case bus.ResponseReceived:
+		rec.Response = data.Text
+		rec.LLMMs = data.LatencyMs
+	case bus.TTSStart:
+		rec.TTSProvider = data.Provider
+		rec.Spoken = data.Text
+	case bus.Subtitle:
+		rec.AudioMs += data.DurationMs // субтитр приходит на каждый кусок длинного ответа
+	case bus.TTSEnd:
+		rec.TTSProvider = data.Provider // фактический провайдер после fallback
+		rec.SynthMs = data.SynthMs
+		rec.PlaybackMs = data.PlaybackMs
+		rec.Interrupted = data.Interrupted
+	case bus.VTubeTrigger:
+		if !data.Reset {
+			rec.VTubeTags = data.Tags
+		}
+		if data.Error != "" {
+			rec.Errors = append(rec.Errors, "vtube: "+data.Error)
+		}
+	case bus.Error:
+		rec.Errors = append(rec.Errors, data.Source+": "+data.Message)
+	case bus.TickEnd:
+		delete(l.open, ev.TickID)
+		rec.Status = data.Status
+		rec.DurationMs = data.DurationMs
+		if data.Error != "" && len(rec.Errors) == 0 {
+			rec.Errors = append(rec.Errors, data.Error)
+		}
+		return rec
+	}
+	return nil
+}
+
+func (l *Logger) write(rec *Record) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		l.logger.Warnw("Audit record marshal failed", "tick", rec.TickID, "error", err)
+		return
+	}
+	if _, err := l.out.Write(append(b, '\n')); err != nil {
+		l.logger.Warnw("Audit write failed", "tick", rec.TickID, "error", err)
+	}
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"OpenAIClient/internal/service/bus"
+	"testing"
+	"time"
+)
+
+func TestApply_MultiChunkTick(t *testing.T) {
+	l := &Logger{model: "gpt", open: map[int64]*Record{}}
+	at := time.Now()
+	events := []any{
+		bus.TickStart{Trigger: "timer", Persona: "Кот"},
+		bus.TTSStart{Provider: "yandex", Text: "Первый кусок. Второй кусок. Третий."},
+		bus.Subtitle{Text: "Первый кусок.", DurationMs: 1200},
+		bus.Subtitle{Text: "Второй кусок.", DurationMs: 900},
+		bus.Subtitle{Text: "Третий.", DurationMs: 400},
+		bus.TTSEnd{Provider: "google", SynthMs: 300, PlaybackMs: 2550},
+		bus.TickEnd{Status: "ok", DurationMs: 4000},
+	}
+	var rec *Record
+	for i, data := range events {
+		rec = l.apply(bus.Event{TickID: 7, At: at, Data: data})
+		if rec != nil && i != len(events)-1 {
+			t.Fatalf("record returned before tick.end at event %d", i)
+		}
+	}
+	if rec == nil {
+		t.Fatalf("no record after tick.end")
+	}
+	if rec.AudioMs != 2500 {
+		t.Fatalf("audio_ms = %d, want 2500 (sum of all chunks)", rec.AudioMs)
+	}
+	if rec.PlaybackMs != 2550 || rec.TTSProvider != "google" || rec.Status != "ok" {
+		t.Fatalf("record = %+v", rec)
+	}
+	if len(l.open) != 0 {
+		t.Fatalf("tick left open after tick.end")
+	}
+}
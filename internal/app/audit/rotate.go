@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// rotator — файл журнала с ротацией: новый файл каждый день и при превышении размера.
+// Имена: audit-2006-01-02.jsonl, затем audit-2006-01-02.1.jsonl, .2 и т.д.
+type rotator struct {
+	dir     string
+	maxSize int64
+
+	f    *os.File
+	day  string
+	part int
+	size int64
+}
+
+func newRotator(dir string, maxSize int64) *rotator {
+	return &rotator{dir: dir, maxSize: maxSize}
+}
+
+// Write дописывает одну запись целиком; ротация только между записями.
+func (r *rotator) Write(p []byte) (int, error) {
+	day := time.Now().Format("2006-01-02")
+	if r.f == nil || day != r.day || (r.maxSize > 0 && r.size+int64(len(p)) > r.maxSize && r.size > 0) {
+		if err := r.rotate(day); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotator) rotate(day string) error {
+	if r.f != nil {
+		_ = r.f.Close()
+		r.f = nil
+	}
+	if day != r.day {
+		r.day, r.part = day, 0
+	} else {
+		r.part++
+	}
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	// Пропускаем уже заполненные части (перезапуск приложения в тот же день)
+	for {
+		path := r.path()
+		fi, err := os.Stat(path)
+		if err == nil && r.maxSize > 0 && fi.Size() >= r.maxSize {
+			r.part++
+			continue
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		r.f = f
+		r.size = 0
+		if fi != nil {
+			r.size = fi.Size()
+		}
+		return nil
+	}
+}
+
+func (r *rotator) path() string {
+	name := "audit-" + r.day + ".jsonl"
+	if r.part > 0 {
+		name = fmt.Sprintf("audit-%s.%d.jsonl", r.day, r.part)
+	}
+	return filepath.Join(r.dir, name)
+}
+
+func (r *rotator) Close() error {
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
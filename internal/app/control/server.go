@@ -0,0 +1,356 @@
+package control
+
+import (
+	"OpenAIClient/internal/app/scheduler"
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/bus"
+	"OpenAIClient/internal/service/chat"
+	"OpenAIClient/internal/service/speech"
+	st "OpenAIClient/internal/service/state"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Максимальный размер тела запроса
+const maxBody = 64 << 10
+
+// Server — локальный HTTP API управления запущенным компаньоном.
+type Server struct {
+	cfg            config.ControlAPIConfig
+	srv            *http.Server
+	mux            *http.ServeMux
+	logger         *zap.SugaredLogger
+	running        atomic.Bool
+	done           chan struct{} // закрывается при остановке: завершает долгие потоки событий
+	tokenGenerated bool          // CONTROL_API_AUTH_TOKEN не задан — токен создан на этот запуск
+
+	sch    *scheduler.Scheduler
+	speech *speech.Speech
+	chat   *chat.Chat
+	state  *st.State
+	events *bus.Bus
+}
+
+// textReq — тело запросов с текстом (say, inject).
+type textReq struct {
+	Text string `json:"text"`
+}
+
+// personaReq — тело запроса смены персонажа.
+type personaReq struct {
+	Name string `json:"name"`
+}
+
+// clearReq — тело запроса очистки буферов; пустой список — все буферы.
+type clearReq struct {
+	Buffers []string `json:"buffers"`
+}
+
+// statusResp — ответ /api/status.
+type statusResp struct {
+	scheduler.Status
+	Buffers map[string]int `json:"buffers"`
+}
+
+func New(cfg config.ControlAPIConfig, sch *scheduler.Scheduler, sp *speech.Speech, ch *chat.Chat, stbuf *st.State, events *bus.Bus, logger *zap.SugaredLogger) *Server {
+	if cfg.BindAddr == "" {
+		cfg.BindAddr = "127.0.0.1:3100"
+	}
+	s := &Server{cfg: cfg, logger: logger, sch: sch, speech: sp, chat: ch, state: stbuf, events: events, mux: http.NewServeMux(), done: make(chan struct{})}
+	// Без токена API не работает: любая страница в браузере стримера могла бы говорить голосом аватара
+	if s.cfg.AuthToken == "" {
+		s.cfg.AuthToken = newToken()
+		s.tokenGenerated = true
+	}
+
+	s.handle("GET /api/status", s.handleStatus)
+	s.handle("POST /api/pause", s.handlePause)
+	s.handle("POST /api/resume", s.handleResume)
+	s.handle("POST /api/tick", s.handleTick)
+	s.handle("POST /api/say", s.handleSay)
+	s.handle("GET /api/personas", s.handlePersonas)
+	s.handle("POST /api/persona", s.handlePersona)
+	s.handle("POST /api/buffers/clear", s.handleClear)
+	s.handle("POST /api/inject/{buffer}", s.handleInject)
+	s.handle("GET /api/events", s.handleSSE)
+	s.handle("GET /api/events/ws", s.handleWS)
+
+	s.srv = &http.Server{
+		Addr:              cfg.BindAddr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	return s
+}
+
+// Handle регистрирует дополнительный обработчик с авторизацией (до Start).
+func (s *Server) Handle(pattern string, h http.Handler) { s.mux.Handle(pattern, s.auth(h)) }
+
+// HandlePublic регистрирует обработчик без авторизации — только для статики без данных (страницы, CSS, JS).
+func (s *Server) HandlePublic(pattern string, h http.Handler) { s.mux.Handle(pattern, h) }
+
+// handle регистрирует встроенный обработчик API с авторизацией.
+func (s *Server) handle(pattern string, fn http.HandlerFunc) { s.Handle(pattern, fn) }
+
+func (s *Server) Start(ctx context.Context) error {
+	if !s.running.CompareAndSwap(false, true) {
+		return nil
+	}
+	if s.tokenGenerated {
+		s.logger.Warnw("CONTROL_API_AUTH_TOKEN is empty: generated a token for this run; set it to keep one across restarts",
+			"token", s.cfg.AuthToken, "dashboard", "http://"+s.cfg.BindAddr+"/?token="+s.cfg.AuthToken)
+	}
+	ln, err := net.Listen("tcp", s.cfg.BindAddr)
+	if err != nil {
+		s.running.Store(false)
+		return err
+	}
+	go func() {
+		s.logger.Infow("Control API listening", "addr", s.cfg.BindAddr)
+		if err := s.srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) && err != nil {
+			s.logger.Errorw("Control API stopped with error", "error", err)
+		} else {
+			s.logger.Infow("Control API stopped")
+		}
+	}()
+
+	// Останавливаем сервер по отмене контекста приложения
+	go func() {
+		<-ctx.Done()
+		_ = s.Stop(context.WithoutCancel(ctx))
+	}()
+	return nil
+}
+
+// Run запускает сервер и блокирует до отмены контекста (для супервизора).
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.Start(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return s.Stop(context.WithoutCancel(ctx))
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	if !s.running.CompareAndSwap(true, false) {
+		return nil
+	}
+	close(s.done)
+	shutdownCtx, cancel := context.WithTimeoutCause(ctx, 5*time.Second, errors.New("control-api shutdown timeout"))
+	defer cancel()
+	if err := s.srv.Shutdown(shutdownCtx); err != nil {
+		s.logger.Warnw("graceful shutdown error", "error", err)
+		return s.srv.Close()
+	}
+	return nil
+}
+
+func (s *Server) Addr() string { return s.cfg.BindAddr }
+
+// auth проверяет источник запроса и токен: заголовок "Authorization: Bearer <token>" или параметр ?token= (для браузерных источников).
+func (s *Server) auth(next http.Handler) http.Handler {
+	want := []byte(s.cfg.AuthToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedOrigin(r) {
+			writeError(w, http.StatusForbidden, "origin not allowed")
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if got == "" {
+			got = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, statusResp{
+		Status: s.sch.Status(),
+		Buffers: map[string]int{
+			"speech": s.speech.Len(),
+			"chat":   s.chat.Len(),
+			"state":  s.state.Len(),
+		},
+	})
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, _ *http.Request) {
+	s.sch.Pause()
+	s.logger.Infow("Control API: paused")
+	writeJSON(w, http.StatusOK, s.sch.Status())
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, _ *http.Request) {
+	s.sch.Resume()
+	s.logger.Infow("Control API: resumed")
+	writeJSON(w, http.StatusOK, s.sch.Status())
+}
+
+func (s *Server) handleTick(w http.ResponseWriter, _ *http.Request) {
+	s.sch.ForceTick()
+	s.logger.Infow("Control API: tick forced")
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleSay(w http.ResponseWriter, r *http.Request) {
+	var req textReq
+	if !readJSON(w, r, &req) {
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		writeError(w, http.StatusBadRequest, "empty text")
+		return
+	}
+	if err := s.sch.Say(text); err != nil {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	s.logger.Infow("Control API: say queued", "text", text)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handlePersonas(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.sch.Personas())
+}
+
+func (s *Server) handlePersona(w http.ResponseWriter, r *http.Request) {
+	var req personaReq
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if err := s.sch.SetPersona(req.Name); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	s.logger.Infow("Control API: persona switched", "persona", req.Name)
+	writeJSON(w, http.StatusOK, s.sch.Status())
+}
+
+func (s *Server) handleClear(w http.ResponseWriter, r *http.Request) {
+	var req clearReq
+	if r.ContentLength != 0 && !readJSON(w, r, &req) {
+		return
+	}
+	names := req.Buffers
+	if len(names) == 0 {
+		names = []string{"speech", "chat", "state"}
+	}
+	cleared := map[string]int{}
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "speech":
+			cleared["speech"] = len(s.speech.Drain())
+		case "chat":
+			cleared["chat"] = len(s.chat.Drain())
+		case "state":
+			cleared["state"] = len(s.state.Drain())
+		default:
+			writeError(w, http.StatusBadRequest, "unknown buffer: "+name)
+			return
+		}
+	}
+	s.logger.Infow("Control API: buffers cleared", "cleared", cleared)
+	writeJSON(w, http.StatusOK, cleared)
+}
+
+func (s *Server) handleInject(w http.ResponseWriter, r *http.Request) {
+	var req textReq
+	if !readJSON(w, r, &req) {
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		writeError(w, http.StatusBadRequest, "empty text")
+		return
+	}
+	buffer := strings.ToLower(r.PathValue("buffer"))
+	switch buffer {
+	case "speech":
+		s.speech.Add(text)
+	case "chat":
+		s.chat.Add(time.Now().Format("15:04:05") + " " + text)
+	case "state":
+		s.state.Add(text)
+	default:
+		writeError(w, http.StatusNotFound, "unknown buffer: "+buffer)
+		return
+	}
+	s.logger.Infow("Control API: message injected", "buffer", buffer, "text", text)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readJSON декодирует тело запроса; при ошибке сам отвечает 400.
+// Только application/json: такие запросы браузер не отправит с чужой страницы без проверки CORS.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	defer r.Body.Close()
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "content type must be application/json")
+		return false
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxBody)).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// allowedOrigin — запрос без Origin (скрипты, Stream Deck), со страницы самого API (дашборд, оверлей OBS)
+// или с локальной страницы. Чужие сайты, открытые у стримера, получают 403.
+func allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false // в том числе "null" (file://, песочница)
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newToken — случайный токен API на один запуск.
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,109 @@
+package control
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Буфер событий одного подписчика и период keep-alive
+const (
+	streamBuf    = 256
+	keepAlive    = 15 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	// Источник уже проверен в auth; браузерные клиенты (OBS, дашборд) приходят со страниц самого API
+	CheckOrigin: allowedOrigin,
+}
+
+// handleSSE отдаёт поток событий в формате Server-Sent Events.
+func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	events, unsubscribe := s.events.Subscribe(streamBuf)
+	defer unsubscribe()
+
+	// Долгое соединение: снимаем таймаут записи сервера
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ping := time.NewTicker(keepAlive)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		case <-ping.C:
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev := <-events:
+			b, err := json.Marshal(ev)
+			if err != nil {
+				s.logger.Warnw("Event marshal failed", "type", ev.Type, "error", err)
+				continue
+			}
+			if _, err := w.Write([]byte("event: " + ev.Type + "\ndata: " + string(b) + "\n\n")); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleWS отдаёт поток событий через WebSocket: одно JSON-событие на сообщение.
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade уже ответил клиенту
+	}
+	defer conn.Close()
+	events, unsubscribe := s.events.Subscribe(streamBuf)
+	defer unsubscribe()
+
+	// Читаем входящие только ради close/ping от клиента
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(keepAlive)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutdown"), time.Now().Add(writeTimeout))
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		case ev := <-events:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package dashboard
+
+import (
+	"OpenAIClient/internal/app/control"
+	"OpenAIClient/internal/service/bus"
+	"OpenAIClient/internal/service/chat"
+	"OpenAIClient/internal/service/health"
+	"OpenAIClient/internal/service/image"
+	"OpenAIClient/internal/service/speech"
+	st "OpenAIClient/internal/service/state"
+	"context"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Ширина миниатюр изображений, отправленных в ИИ
+const thumbWidth = 320
+
+//go:embed static
+var static embed.FS
+
+// TickRecord — собранная из событий шины запись об одном тике.
+type TickRecord struct {
+	ID          int64           `json:"id"`
+	Trigger     string          `json:"trigger"`
+	Persona     string          `json:"persona"`
+	StartedAt   time.Time       `json:"started_at"`
+	Status      string          `json:"status"` // пусто — тик ещё идёт
+	DurationMs  int64           `json:"duration_ms"`
+	Error       string          `json:"error,omitempty"`
+	Prompt      *bus.PromptSent `json:"prompt,omitempty"`
+	Images      int             `json:"images"` // число доступных миниатюр
+	Response    string          `json:"response,omitempty"`
+	LLMMs       int64           `json:"llm_ms"`
+	TTSProvider string          `json:"tts_provider,omitempty"`
+	SynthMs     int64           `json:"synth_ms"`
+	PlaybackMs  int64           `json:"playback_ms"`
+	Interrupted bool            `json:"interrupted,omitempty"`
+	VTubeTags   []string        `json:"vtube_tags,omitempty"`
+
+	thumbs [][]byte
+}
+
+// Dashboard — встроенная веб-панель оператора: последние тики, буферы и здоровье подсистем.
+type Dashboard struct {
+	limit  int
+	logger *zap.SugaredLogger
+	health *health.Registry
+	speech *speech.Speech
+	chat   *chat.Chat
+	state  *st.State
+
+	events      <-chan bus.Event
+	unsubscribe func()
+
+	mu    sync.RWMutex
+	ticks []*TickRecord // от старых к новым, не больше limit
+}
+
+// New создаёт панель и сразу подписывается на шину, чтобы не пропустить первые тики.
+func New(limit int, events *bus.Bus, reg *health.Registry, sp *speech.Speech, ch *chat.Chat, stbuf *st.State, logger *zap.SugaredLogger) *Dashboard {
+	if limit <= 0 {
+		limit = 20
+	}
+	sub, unsubscribe := events.Subscribe(512)
+	return &Dashboard{limit: limit, logger: logger, health: reg, speech: sp, chat: ch, state: stbuf, events: sub, unsubscribe: unsubscribe}
+}
+
+// Run собирает записи о тиках из событий до отмены контекста.
+func (d *Dashboard) Run(ctx context.Context) {
+	defer d.unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-d.events:
+			if !ok {
+				return
+			}
+			d.apply(ev)
+		}
+	}
+}
+
+// Register подключает страницы и API панели к серверу Control API.
+func (d *Dashboard) Register(srv *control.Server) {
+	sub, _ := fs.Sub(static, "static")
+	// Статика публична (токен не нужен для CSS/JS); данные идут через API с авторизацией
+	srv.HandlePublic("GET /dashboard/", http.StripPrefix("/dashboard/", http.FileServerFS(sub)))
+	srv.HandlePublic("GET /{$}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := "/dashboard/"
+		if q := r.URL.RawQuery; q != "" {
+			target += "?" + q
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+	}))
+	srv.Handle("GET /api/dashboard/ticks", http.HandlerFunc(d.handleTicks))
+	srv.Handle("GET /api/dashboard/ticks/{id}/images/{n}", http.HandlerFunc(d.handleImage))
+	srv.Handle("GET /api/dashboard/buffers", http.HandlerFunc(d.handleBuffers))
+	srv.Handle("GET /api/dashboard/health", http.HandlerFunc(d.handleHealth))
+}
+
+// apply обновляет запись тика и здоровье подсистем по событию.
+func (d *Dashboard) apply(ev bus.Event) {
+	if ev.Type == bus.TypeTickStart {
+		data, _ := ev.Data.(bus.TickStart)
+		d.mu.Lock()
+		d.ticks = append(d.ticks, &TickRecord{ID: ev.TickID, Trigger: data.Trigger, Persona: data.Persona, StartedAt: ev.At})
+		if over := len(d.ticks) - d.limit; over > 0 {
+			d.ticks = append(d.ticks[:0:0], d.ticks[over:]...)
+		}
+		d.mu.Unlock()
+		return
+	}
+
+	// Миниатюры готовим вне блокировки: чтение и масштабирование JPEG небыстрые
+	var thumbs [][]byte
+	if data, ok := ev.Data.(bus.PromptSent); ok {
+		for _, p := range data.ImagePaths {
+			b, err := image.Thumbnail(p, thumbWidth)
+			if err != nil {
+				d.logger.Debugw("Dashboard thumbnail failed", "path", p, "error", err)
+				continue
+			}
+			thumbs = append(thumbs, b)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	rec := d.find(ev.TickID)
+	switch data := ev.Data.(type) {
+	case bus.PromptSent:
+		if rec != nil {
+			rec.Prompt = &data
+			rec.thumbs = thumbs
+			rec.Images = len(thumbs)
+		}
+	case bus.ResponseReceived:
+		d.health.Set("llm", health.StateOK, "")
+		if rec != nil {
+			rec.Response = data.Text
+			rec.LLMMs = data.LatencyMs
+		}
+	case bus.TTSStart:
+		if rec != nil {
+			rec.TTSProvider = data.Provider
+		}
+	case bus.TTSEnd:
+		d.health.Set("tts", health.StateOK, data.Provider)
+		if rec != nil {
+			rec.SynthMs = data.SynthMs
+			rec.PlaybackMs = data.PlaybackMs
+			rec.Interrupted = data.Interrupted
+		}
+	case bus.VTubeTrigger:
+		if data.Error != "" {
+			d.health.Set("vtube", health.StateDegraded, data.Error)
+		} else {
+			d.health.Set("vtube", health.StateOK, "")
+		}
+		if rec != nil && !data.Reset {
+			rec.VTubeTags = data.Tags
+		}
+	case bus.TickEnd:
+		if data.Status == "error" {
+			d.health.Set("scheduler", health.StateDegraded, data.Error)
+		} else {
+			d.health.Set("scheduler", health.StateOK, "")
+		}
+		if rec != nil {
+			rec.Status = data.Status
+			rec.DurationMs = data.DurationMs
+			rec.Error = data.Error
+		}
+	case bus.Error:
+		if data.Source == "llm" || data.Source == "tts" {
+			d.health.Set(data.Source, health.StateDegraded, data.Message)
+		}
+	}
+}
+
+// find ищет запись тика по ID; вызывается под d.mu.
+func (d *Dashboard) find(id int64) *TickRecord {
+	for i := len(d.ticks) - 1; i >= 0; i-- {
+		if d.ticks[i].ID == id {
+			return d.ticks[i]
+		}
+	}
+	return nil
+}
+
+func (d *Dashboard) handleTicks(w http.ResponseWriter, _ *http.Request) {
+	d.mu.RLock()
+	out := make([]TickRecord, 0, len(d.ticks))
+	for i := len(d.ticks) - 1; i >= 0; i-- { // новые сверху
+		out = append(out, *d.ticks[i])
+	}
+	d.mu.RUnlock()
+	writeJSON(w, out)
+}
+
+func (d *Dashboard) handleImage(w http.ResponseWriter, r *http.Request) {
+	id, err1 := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	n, err2 := strconv.Atoi(r.PathValue("n"))
+	if err1 != nil || err2 != nil {
+		http.NotFound(w, r)
+		return
+	}
+	d.mu.RLock()
+	var img []byte
+	if rec := d.find(id); rec != nil && n >= 0 && n < len(rec.thumbs) {
+		img = rec.thumbs[n]
+	}
+	d.mu.RUnlock()
+	if img == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	_, _ = w.Write(img)
+}
+
+func (d *Dashboard) handleBuffers(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string][]string{
+		"speech": d.speech.Snapshot(),
+		"chat":   d.chat.Snapshot(),
+		"state":  d.state.Snapshot(),
+	})
+}
+
+func (d *Dashboard) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, d.health.List())
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
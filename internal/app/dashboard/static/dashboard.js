@@ -0,0 +1,124 @@
+// Панель оператора: опрос API Control раз в 2 секунды и кнопки управления.
+const token = new URLSearchParams(location.search).get('token') || '';
+const auth = token ? { Authorization: 'Bearer ' + token } : {};
+const q = token ? '?token=' + encodeURIComponent(token) : '';
+
+async function api(method, path, body) {
+  const opts = { method, headers: { ...auth } };
+  if (body !== undefined) {
+    opts.headers['Content-Type'] = 'application/json';
+    opts.body = JSON.stringify(body);
+  }
+  const resp = await fetch(path, opts);
+  if (!resp.ok) throw new Error(method + ' ' + path + ': ' + resp.status);
+  const type = resp.headers.get('Content-Type') || '';
+  return type.includes('json') ? resp.json() : null;
+}
+
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  Object.assign(e, attrs || {});
+  for (const c of children) e.append(c);
+  return e;
+}
+
+function renderStatus(s) {
+  const box = document.getElementById('status');
+  box.replaceChildren(
+    el('span', { className: s.paused ? 'degraded' : 'ok' }, s.paused ? 'на паузе' : 'работает'),
+    el('span', {}, 'персонаж: ' + (s.persona || '—') + (s.pinned_persona ? ' (закреплён)' : '')),
+    el('span', {}, 'TTS: ' + s.tts_service),
+    el('span', {}, 'буферы: ' + Object.entries(s.buffers || {}).map(([k, v]) => k + '=' + v).join(' ')),
+  );
+}
+
+function renderTicks(ticks) {
+  const box = document.getElementById('ticks');
+  box.replaceChildren(...ticks.map(t => {
+    const head = el('div', { className: 'head' },
+      el('b', {}, '#' + t.id),
+      el('span', { className: t.status || 'degraded' }, t.status || 'идёт'),
+      el('span', {}, new Date(t.started_at).toLocaleTimeString()),
+      el('span', {}, t.trigger),
+      el('span', {}, t.persona),
+      el('span', {}, 'всего ' + t.duration_ms + ' мс · ИИ ' + t.llm_ms + ' мс · TTS ' + t.synth_ms + ' мс · звук ' + t.playback_ms + ' мс'),
+    );
+    const node = el('div', { className: 'tick' }, head);
+    if (t.error) node.append(el('div', { className: 'error' }, t.error));
+    if (t.response) node.append(el('div', { className: 'response' }, t.response + (t.interrupted ? ' ✂' : '')));
+    if (t.vtube_tags) node.append(el('div', {}, 'VTube: ' + t.vtube_tags.join(', ')));
+    for (let i = 0; i < t.images; i++) {
+      node.append(el('img', { src: '/api/dashboard/ticks/' + t.id + '/images/' + i + q, loading: 'lazy' }));
+    }
+    if (t.prompt) {
+      const p = t.prompt;
+      node.append(el('details', {},
+        el('summary', {}, 'Промпты (речь ' + p.speech + ', чат ' + p.chat + ', состояние ' + p.state + ', картинки ' + p.images + ')'),
+        el('h2', {}, 'system'), el('pre', {}, p.system_prompt || ''),
+        el('h2', {}, 'assistant'), el('pre', {}, p.assistant_prompt || ''),
+        el('h2', {}, 'user'), el('pre', {}, p.user_prompt || ''),
+      ));
+    }
+    return node;
+  }));
+}
+
+function renderHealth(items) {
+  document.getElementById('health').replaceChildren(...items.map(h =>
+    el('tr', {}, el('td', {}, h.name), el('td', { className: h.state }, h.state), el('td', {}, h.detail || ''))));
+}
+
+function renderBuffers(b) {
+  const box = document.getElementById('buffers');
+  box.replaceChildren(...Object.entries(b).flatMap(([name, msgs]) => [
+    el('b', {}, name + ' (' + msgs.length + ')'),
+    el('ul', {}, ...msgs.map(m => el('li', {}, m))),
+  ]));
+}
+
+async function refresh() {
+  try {
+    const [status, ticks, health, buffers] = await Promise.all([
+      api('GET', '/api/status'), api('GET', '/api/dashboard/ticks'),
+      api('GET', '/api/dashboard/health'), api('GET', '/api/dashboard/buffers'),
+    ]);
+    renderStatus(status);
+    renderTicks(ticks);
+    renderHealth(health);
+    renderBuffers(buffers);
+    const sel = document.getElementById('persona');
+    if (document.activeElement !== sel) sel.value = status.pinned_persona || 'random';
+  } catch (e) {
+    document.getElementById('status').textContent = 'нет связи: ' + e.message;
+  }
+}
+
+async function loadPersonas() {
+  const sel = document.getElementById('persona');
+  const names = await api('GET', '/api/personas');
+  sel.replaceChildren(el('option', { value: 'random' }, 'случайный'), ...names.map(n => el('option', { value: n }, n)));
+  sel.onchange = () => api('POST', '/api/persona', { name: sel.value }).then(refresh);
+}
+
+document.querySelectorAll('button[data-action]').forEach(b => {
+  b.onclick = () => {
+    const path = b.dataset.action === 'clear' ? '/api/buffers/clear' : '/api/' + b.dataset.action;
+    api('POST', path).then(refresh).catch(e => alert(e.message));
+  };
+});
+
+document.getElementById('say').onsubmit = e => {
+  e.preventDefault();
+  const f = e.target;
+  api('POST', '/api/say', { text: f.text.value }).then(() => { f.text.value = ''; }).catch(err => alert(err.message));
+};
+
+document.getElementById('inject').onsubmit = e => {
+  e.preventDefault();
+  const f = e.target;
+  api('POST', '/api/inject/' + f.buffer.value, { text: f.text.value }).then(() => { f.text.value = ''; refresh(); }).catch(err => alert(err.message));
+};
+
+loadPersonas().catch(() => {});
+refresh();
+setInterval(refresh, 2000);
@@ -0,0 +1,40 @@
+package overlay
+
+import (
+	"OpenAIClient/internal/app/control"
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+//go:embed static
+var static embed.FS
+
+// Overlay — страница субтитров для OBS (browser source). Текст приходит из потока событий Control API (тип subtitle).
+type Overlay struct {
+	cssPath string // свой CSS вместо встроенного; пусто — встроенный
+	logger  *zap.SugaredLogger
+}
+
+func New(cssPath string, logger *zap.SugaredLogger) *Overlay {
+	return &Overlay{cssPath: cssPath, logger: logger}
+}
+
+// Register подключает страницу оверлея к серверу Control API.
+func (o *Overlay) Register(srv *control.Server) {
+	sub, _ := fs.Sub(static, "static")
+	files := http.StripPrefix("/overlay/", http.FileServerFS(sub))
+	// Статика публична: OBS передаёт токен в URL страницы, а JS — в подключение к потоку событий
+	srv.HandlePublic("GET /overlay/", files)
+	if o.cssPath != "" {
+		srv.HandlePublic("GET /overlay/overlay.css", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Читаем файл на каждый запрос: правки CSS видны после обновления источника в OBS
+			w.Header().Set("Content-Type", "text/css; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-cache")
+			http.ServeFile(w, r, o.cssPath)
+		}))
+		o.logger.Infow("Overlay uses custom CSS", "path", o.cssPath)
+	}
+}
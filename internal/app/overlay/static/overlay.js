@@ -0,0 +1,72 @@
+// Оверлей субтитров: слушает поток событий Control API и показывает текущую реплику.
+// Параметры URL: token — токен API, words=1 — показывать по словам, hold — сколько мс держать текст после речи.
+const params = new URLSearchParams(location.search);
+const token = params.get('token') || '';
+const wordByWord = params.get('words') === '1';
+const hold = Number(params.get('hold') || 1500);
+// Если длительность аудио неизвестна — оцениваем по скорости чтения
+const msPerChar = 70;
+
+const box = document.getElementById('subtitle');
+const personaEl = document.getElementById('persona');
+const emotionEl = document.getElementById('emotion');
+const textEl = document.getElementById('text');
+let timers = [];
+
+function clearTimers() {
+  timers.forEach(clearTimeout);
+  timers = [];
+}
+
+function hide(delay) {
+  timers.push(setTimeout(() => box.classList.add('hidden'), delay));
+}
+
+function show(s) {
+  clearTimers();
+  const duration = s.duration_ms > 0 ? s.duration_ms : s.text.length * msPerChar;
+  personaEl.textContent = s.persona || '';
+  emotionEl.textContent = (s.emotion || []).join(', ');
+  if (wordByWord) {
+    const words = s.text.split(/\s+/).filter(Boolean);
+    const step = duration / Math.max(words.length, 1);
+    textEl.replaceChildren(...words.map((w, i) => {
+      const span = document.createElement('span');
+      span.className = 'word';
+      span.textContent = w + ' ';
+      timers.push(setTimeout(() => span.classList.add('shown'), i * step));
+      return span;
+    }));
+  } else {
+    textEl.textContent = s.text;
+  }
+  box.classList.remove('hidden');
+  hide(duration + hold);
+}
+
+function handle(ev) {
+  if (ev.type === 'subtitle') {
+    show(ev.data);
+  } else if (ev.type === 'tts.end' && ev.data.interrupted) {
+    // Речь прервана (barge-in) — убираем субтитры сразу
+    clearTimers();
+    hide(0);
+  }
+}
+
+function connect() {
+  const proto = location.protocol === 'https:' ? 'wss:' : 'ws:';
+  const q = token ? '?token=' + encodeURIComponent(token) : '';
+  const ws = new WebSocket(proto + '//' + location.host + '/api/events/ws' + q);
+  ws.onmessage = m => {
+    try {
+      handle(JSON.parse(m.data));
+    } catch (e) {
+      console.warn('bad event', e);
+    }
+  };
+  // Компаньон перезапускается — переподключаемся
+  ws.onclose = () => setTimeout(connect, 2000);
+}
+
+connect();
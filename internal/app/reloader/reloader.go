@@ -0,0 +1,140 @@
+package reloader
+
+import (
+	"OpenAIClient/internal/app/control"
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/bus"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// Пауза после последнего изменения файла перед перезагрузкой: редакторы пишут файл в несколько приёмов
+const debounce = 300 * time.Millisecond
+
+// Target — получатель нового конфига (Scheduler, Requester).
+type Target interface {
+	SetConfig(cfg *config.Config)
+}
+
+// Result — итог перезагрузки.
+type Result struct {
+	Applied         []string `json:"applied"`          // применённые на лету настройки
+	RestartRequired []string `json:"restart_required"` // изменённые настройки, требующие перезапуска
+}
+
+// Reloader перечитывает конфиг по изменению файлов или по команде и атомарно подменяет его в получателях.
+// При ошибке разбора остаётся старый конфиг.
+type Reloader struct {
+	path     string
+	validate func(*config.Config) error
+	events   *bus.Bus
+	logger   *zap.SugaredLogger
+	targets  []Target
+
+	mu  sync.Mutex
+	cur *config.Config
+}
+
+// New создаёт перезагрузчик. path — файл .env; cfg — конфиг, с которым запущено приложение;
+// validate — проверка нового конфига, та же, что при запуске: конфиг, который не запустился бы, не применяется.
+func New(path string, cfg *config.Config, validate func(*config.Config) error, events *bus.Bus, logger *zap.SugaredLogger, targets ...Target) *Reloader {
+	if path == "" {
+		path = ".env"
+	}
+	return &Reloader{path: path, validate: validate, cur: cfg, events: events, logger: logger, targets: targets}
+}
+
+// Reload перечитывает конфиг и применяет горячие настройки.
+func (r *Reloader) Reload() (Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next, err := config.Reload(r.path, r.validate)
+	if err != nil {
+		r.logger.Errorw("Config reload failed; keeping current config", "error", err)
+		r.events.Publish(bus.TypeConfigReload, 0, bus.ConfigReload{Error: err.Error()})
+		return Result{}, err
+	}
+	hot, restart := config.Diff(r.cur, next)
+	res := Result{Applied: hot, RestartRequired: restart}
+	if len(hot) > 0 {
+		r.cur = config.ApplyHot(r.cur, next)
+		for _, t := range r.targets {
+			t.SetConfig(r.cur)
+		}
+	}
+	r.logger.Infow("Config reloaded", "applied", hot, "restartRequired", restart)
+	r.events.Publish(bus.TypeConfigReload, 0, bus.ConfigReload{Applied: hot, RestartRequired: restart})
+	return res, nil
+}
+
+// Run следит за .env и файлом конфига до отмены контекста. Следим за папкой: редакторы часто заменяют файл целиком.
+func (r *Reloader) Run(ctx context.Context) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	// .env и файл конфига (если есть); папку каждого добавляем один раз
+	watched := map[string]bool{}
+	for _, p := range []string{r.path, config.FilePath()} {
+		if p == "" {
+			continue
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		if !watched[abs] {
+			watched[abs] = true
+			if err := w.Add(filepath.Dir(abs)); err != nil {
+				return err
+			}
+			r.logger.Infow("Watching config for changes", "path", abs)
+		}
+	}
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return err // супервизор перезапустит наблюдение
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if watched[filepath.Clean(ev.Name)] && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				timer.Reset(debounce)
+			}
+		case <-timer.C:
+			_, _ = r.Reload()
+		}
+	}
+}
+
+// Register подключает команду перезагрузки к Control API: POST /api/config/reload.
+func (r *Reloader) Register(srv *control.Server) {
+	srv.Handle("POST /api/config/reload", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		res, err := r.Reload()
+		if err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+}
@@ -4,12 +4,15 @@ import (
 	"OpenAIClient/internal/adapter/localconversation"
 	"OpenAIClient/internal/config"
 	"OpenAIClient/internal/consts"
+	"OpenAIClient/internal/service/bus"
 	"OpenAIClient/internal/service/chat"
 	"OpenAIClient/internal/service/companion"
 	"OpenAIClient/internal/service/image"
+	"OpenAIClient/internal/service/metrics"
 	"OpenAIClient/internal/service/notify"
 	"OpenAIClient/internal/service/speech"
 	st "OpenAIClient/internal/service/state"
+	"OpenAIClient/internal/service/tts/markup"
 	"cmp"
 	"context"
 	"errors"
@@ -19,13 +22,14 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 )
 
 type Requester struct {
-	cfg       *config.Config
+	conf      atomic.Pointer[config.Config] // текущий конфиг; читать через cfg()
 	companion *companion.Companion
 	logger    *zap.SugaredLogger
 	localConv *localconversation.LocalConversation
@@ -33,12 +37,12 @@ type Requester struct {
 	state     *st.State
 	chat      *chat.Chat
 	notifier  *notify.SoundNotifier
+	events    *bus.Bus
 	rnd       *rand.Rand
 }
 
-func New(cfg *config.Config, companion *companion.Companion, sp *speech.Speech, stbuf *st.State, ch *chat.Chat, notifier *notify.SoundNotifier, logger *zap.SugaredLogger) *Requester {
+func New(cfg *config.Config, companion *companion.Companion, sp *speech.Speech, stbuf *st.State, ch *chat.Chat, notifier *notify.SoundNotifier, events *bus.Bus, logger *zap.SugaredLogger) *Requester {
 	r := &Requester{
-		cfg:       cfg,
 		companion: companion,
 		logger:    logger,
 		localConv: localconversation.New("", cfg.MaxHistoryRecords),
@@ -46,16 +50,35 @@ func New(cfg *config.Config, companion *companion.Companion, sp *speech.Speech,
 		state:     stbuf,
 		chat:      ch,
 		notifier:  notifier,
+		events:    events,
 		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
+	r.conf.Store(cfg)
+	// Размеры входных буферов для /metrics
+	if sp != nil {
+		metrics.RegisterBuffer("speech", sp.Len)
+	}
+	if ch != nil {
+		metrics.RegisterBuffer("chat", ch.Len)
+	}
+	if stbuf != nil {
+		metrics.RegisterBuffer("state", stbuf.Len)
+	}
 	return r
 }
 
+// cfg возвращает текущий конфиг (меняется через SetConfig).
+func (r *Requester) cfg() *config.Config { return r.conf.Load() }
+
+// SetConfig атомарно подменяет конфиг: новые промпты и заголовки применяются со следующего запроса.
+func (r *Requester) SetConfig(cfg *config.Config) { r.conf.Store(cfg) }
+
 // Ранее возвращался Response с Tags для VTube. Теперь Requester не знает о тегах
 // и возвращает только текст ответа.
 
 // SendMessage выполняет сценарий «Послать запрос» один раз.
 func (r *Requester) SendMessage(ctx context.Context, characterItem *config.CharacterItem) (string, error) {
+	cfg := r.cfg() // снимок на весь запрос
 	var userPrompt string
 
 	var b strings.Builder
@@ -85,11 +108,11 @@ func (r *Requester) SendMessage(ctx context.Context, characterItem *config.Chara
 	}
 
 	// Нужно ли добавлять дефолтный промпт речи стримера
-	includePrompt := r.cfg.SpeechDefaultEnabled && len(speechMsgs) == 0 && len(chatMsgs) == 0
+	includePrompt := cfg.SpeechDefaultEnabled && len(speechMsgs) == 0 && len(chatMsgs) == 0
 
 	// Добавить заголовок к речи стримера
-	if (len(speechMsgs) > 0 || includePrompt) && strings.TrimSpace(r.cfg.SpeechHeader) != "" {
-		b.WriteString(r.cfg.SpeechHeader)
+	if (len(speechMsgs) > 0 || includePrompt) && strings.TrimSpace(cfg.SpeechHeader) != "" {
+		b.WriteString(cfg.SpeechHeader)
 	}
 
 	// Добавим сообщения речи
@@ -101,8 +124,8 @@ func (r *Requester) SendMessage(ctx context.Context, characterItem *config.Chara
 	// Если нужно, добавим один случайный prompt из списка
 	if includePrompt {
 		msg := "доложи статус"
-		if n := len(r.cfg.SpeechPrompt); n > 0 {
-			msg = r.cfg.SpeechPrompt[r.rnd.Intn(n)]
+		if n := len(cfg.SpeechPrompt); n > 0 {
+			msg = cfg.SpeechPrompt[r.rnd.Intn(n)]
 		}
 		b.WriteString("\n- ")
 		b.WriteString(msg)
@@ -112,19 +135,23 @@ func (r *Requester) SendMessage(ctx context.Context, characterItem *config.Chara
 	userSpeech := userPrompt
 
 	// Найти последние N картинок
-	paths, err := r.pickLastImages(r.cfg.ImagesSourceDir, r.cfg.ImagesToPick)
+	paths, err := r.pickLastImages(cfg.ImagesSourceDir, cfg.ImagesToPick)
 	if err != nil {
 		return "", err
 	}
 	// Новая логика: если нет И изображений, И сообщений из State — не отправляем
 	if len(paths) == 0 && len(stateMsgs) == 0 {
-		r.logger.Infow("Нет данных для отправки: нет изображений и нет сообщений из State", "dir", r.cfg.ImagesSourceDir)
+		r.logger.Infow("Нет данных для отправки: нет изображений и нет сообщений из State", "dir", cfg.ImagesSourceDir)
 		return "", nil
 	}
 
 	// Подготовить метаданные изображений для отправки (без доп. обработки)
 	processed := make([]image.ProcessedImage, 0, len(paths))
+	var uploadBytes int64
 	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil {
+			uploadBytes += fi.Size()
+		}
 		processed = append(processed, image.ProcessedImage{
 			Path:     p,
 			MimeType: "image/jpeg",
@@ -142,7 +169,7 @@ func (r *Requester) SendMessage(ctx context.Context, characterItem *config.Chara
 	history := r.localConv.History()
 	historyWithHeader := history
 	if len(history) > 0 {
-		header := r.cfg.HistoryHeader
+		header := cfg.HistoryHeader
 		if strings.TrimSpace(header) == "" {
 			header = "история предыдущих ответов AI:"
 		}
@@ -159,7 +186,7 @@ func (r *Requester) SendMessage(ctx context.Context, characterItem *config.Chara
 
 	// ВСТАВИТЬ блок сообщений из чата в самый конец промпта пользователя
 	if len(chatMsgs) > 0 {
-		header := r.cfg.ChatHistoryHeader
+		header := cfg.ChatHistoryHeader
 		if strings.TrimSpace(header) == "" {
 			header = "Сообщения из чата"
 		}
@@ -178,16 +205,20 @@ func (r *Requester) SendMessage(ctx context.Context, characterItem *config.Chara
 	}
 
 	// Подготовить assistantPrompt: возможно добавить блок State в самый низ
-	assistantPrompt := r.cfg.AssistantPrompt
+	assistantPrompt := cfg.AssistantPrompt
 	//Количество предложений в ответе AI
-	n := r.cfg.AssistantSentences
+	n := cfg.AssistantSentences
 	if len(chatMsgs) > 0 {
 		n++
 	}
 	assistantPrompt = fmt.Sprintf(assistantPrompt, n)
+	// Разметка речи: модель может ставить паузы, выделение, шёпот — TTS переведёт их под провайдера
+	if cfg.TTSMarkup {
+		assistantPrompt = assistantPrompt + "\n" + markup.Hint
+	}
 
 	if len(stateMsgs) > 0 {
-		header := strings.TrimSpace(r.cfg.StateHeader)
+		header := strings.TrimSpace(cfg.StateHeader)
 		if header == "" {
 			header = "Состояние игры"
 		}
@@ -212,10 +243,26 @@ func (r *Requester) SendMessage(ctx context.Context, characterItem *config.Chara
 			r.logger.Debugw("Ошибка проигрывания звука уведомления (пропускаем)", "error", err)
 		}
 	}
+	r.events.Publish(bus.TypePromptSent, bus.TickID(ctx), bus.PromptSent{
+		Speech:          len(speechMsgs),
+		Chat:            len(chatMsgs),
+		State:           len(stateMsgs),
+		Images:          len(processed),
+		ImagePaths:      paths,
+		SystemPrompt:    characterPrompt,
+		AssistantPrompt: assistantPrompt,
+		UserPrompt:      userPrompt,
+	})
+	if len(processed) > 0 {
+		metrics.ImageUploadBytes.Observe(float64(uploadBytes))
+	}
+	sent := time.Now()
 	resp, err := r.companion.SendMessageWithImage(ctx, characterPrompt, assistantPrompt, userPrompt, processed)
 	if err != nil {
 		return "", err
 	}
+	metrics.LLMLatency.Observe(time.Since(sent).Seconds())
+	r.events.Publish(bus.TypeResponseReceived, bus.TickID(ctx), bus.ResponseReceived{Text: resp, LatencyMs: time.Since(sent).Milliseconds()})
 	// Сохраняем ответ (локальный лимит истории применяется внутри localConv)
 	r.localConv.AppendResponse(resp)
 	return resp, nil
@@ -235,7 +282,7 @@ func (r *Requester) pickLastImages(dir string, n int) ([]string, error) {
 	}
 
 	// Ограничиваем свежесть изображений: не старше TickTimeoutSeconds
-	maxAge := time.Duration(r.cfg.TickTimeoutSeconds) * time.Second
+	maxAge := time.Duration(r.cfg().TickTimeoutSeconds) * time.Second
 	if maxAge <= 0 {
 		maxAge = 30 * time.Second
 	}
@@ -3,23 +3,31 @@ package scheduler
 import (
 	"OpenAIClient/internal/app/requester"
 	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/bus"
 	"OpenAIClient/internal/service/image"
+	"OpenAIClient/internal/service/metrics"
 	"OpenAIClient/internal/service/notify"
 	"OpenAIClient/internal/service/speech"
 	"OpenAIClient/internal/service/tts"
-	"OpenAIClient/internal/service/tts/gemini"
-	"OpenAIClient/internal/service/tts/google"
+	"OpenAIClient/internal/service/tts/cache"
+	"OpenAIClient/internal/service/tts/chunk"
+	"OpenAIClient/internal/service/tts/fallback"
+	"OpenAIClient/internal/service/tts/markup"
+	"OpenAIClient/internal/service/tts/normalize"
 	"OpenAIClient/internal/service/tts/player"
-	"OpenAIClient/internal/service/tts/yandex"
 	"OpenAIClient/internal/service/vtube"
+	"cmp"
 	"context"
 	"errors"
+	"io"
 	"math/rand"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/openai/openai-go/v3"
 	"go.uber.org/zap"
 )
 
@@ -29,74 +37,145 @@ const (
 	overlapPreempt = "preempt"
 )
 
+// Причины запуска тика (поле trigger в событиях)
+const (
+	triggerTimer   = "timer"
+	triggerEarly   = "early"
+	triggerForced  = "forced"
+	triggerSay     = "say"
+	triggerBargeIn = "barge-in"
+)
+
+// Barge-in режимы: реакция на речь стримера во время тика
+const (
+	bargeInOff  = "off"
+	bargeInStop = "stop"
+	bargeInFade = "fade"
+)
+
+// errBargeIn — причина отмены тика, когда стример заговорил поверх компаньона.
+var errBargeIn = errors.New("barge-in: streamer started speaking")
+
+// ErrSayQueueFull — очередь фраз для озвучки переполнена.
+var ErrSayQueueFull = errors.New("say queue is full")
+
+// ErrUnknownPersona — персонаж с таким именем или индексом не найден в CHARACTER_LIST.
+var ErrUnknownPersona = errors.New("unknown persona")
+
+// Status — снимок состояния планировщика для внешнего управления.
+type Status struct {
+	Paused        bool      `json:"paused"`
+	Running       bool      `json:"running"`
+	TTSService    string    `json:"tts_service"`
+	Persona       string    `json:"persona"`        // персонаж последнего тика
+	PinnedPersona string    `json:"pinned_persona"` // закреплённый персонаж; пусто — случайный выбор
+	LastTickAt    time.Time `json:"last_tick_at"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
 type Scheduler struct {
-	cfg      *config.Config
+	conf     atomic.Pointer[config.Config] // текущий конфиг; читать через cfg()
 	req      *requester.Requester
 	speech   *speech.Speech
-	tts      tts.Synthesizer
+	tts      *fallback.Chain
 	player   player.Player
+	service  string // основной TTS-провайдер (первый в цепочке)
 	notifier *notify.SoundNotifier
 	logger   *zap.SugaredLogger
 	cleaner  *image.Cleaner
 	vts      *vtube.Client
+	events   *bus.Bus
 
 	running    atomic.Bool
+	playing    atomic.Bool // речь тика звучит — при остановке даём договорить
 	mu         sync.Mutex
 	cancelPrev context.CancelFunc
 	gen        int64 // Счётчик текущего тика
 
 	consecutiveErrors int // счётчик ошибок
+
+	// Внешнее управление (control API)
+	paused   atomic.Bool
+	forceCh  chan struct{} // внеочередной тик
+	sayCh    chan string   // фразы для озвучки без запроса к ИИ
+	pinned   int           // индекс закреплённого персонажа; -1 — случайный (под mu)
+	persona  string        // персонаж последнего тика (под mu)
+	lastTick time.Time     // время начала последнего тика (под mu)
+	lastErr  string        // ошибка последнего тика (под mu)
 }
 
-func New(cfg *config.Config, req *requester.Requester, sp *speech.Speech, logger *zap.SugaredLogger, vts *vtube.Client) *Scheduler {
-	// Инициализируем плеер: для Yandex — учитываем внешнюю громкость; для Google — отдаём на откуп VolumeGainDb
-	var p *player.Default
-	service := strings.ToLower(strings.TrimSpace(cfg.TTSService))
-	switch service {
-	case "yandex", "yc", "speechkit":
-		v := max(0, min(100, cfg.YandexTTS.Volume))
-		volDB := float64(v-100) / 5.0
-		p = player.NewWithVolume(volDB)
-	default: // google/gemini по умолчанию — громкость регулируется на стороне провайдера
-		p = player.New()
-		if service == "" {
-			service = "google"
-		}
+func New(cfg *config.Config, oClient *openai.Client, req *requester.Requester, sp *speech.Speech, ply player.Player, events *bus.Bus, logger *zap.SugaredLogger, vts *vtube.Client) *Scheduler {
+	// Цепочка TTS-провайдеров: при ошибке синтеза — следующий по порядку; повторные фразы — из дискового кэша
+	names := cfg.TTSChain()
+	providers := make([]tts.Provider, 0, len(names))
+	var store *cache.Store // один на цепочку: общий лимит размера и общие метрики
+	if cfg.TTSCache.Enabled {
+		store = cache.New(cfg.TTSCache, logger)
 	}
-
-	// Конкретный клиент
-	var synth tts.Synthesizer
-	switch service {
-	case "yandex":
-		synth = yandex.New()
-	case "gemini", "google-gemini":
-		synth = gemini.New(logger)
-	default: // google
-		synth = google.New(logger)
+	for _, name := range names {
+		p, err := tts.Build(name, cfg, tts.Deps{OpenAI: oClient, Logger: logger})
+		if err != nil {
+			// Имена проверены при запуске (tts.Validate); сюда попадаем только при ошибке в коде
+			logger.Errorw("TTS provider skipped", "provider", name, "error", err)
+			continue
+		}
+		if store != nil {
+			p.Synthesizer = store.Wrap(p.Synthesizer, name, p.Settings)
+		}
+		providers = append(providers, p)
 	}
+	chain := fallback.New(providers, normalize.New(cfg.TTSNormalize, logger), cfg.TTSFallbackCooldown, logger)
+	service := names[0]
 
 	// Нотификатор звука (два типа): получение ответа ИИ и перед TTS
-	notifier := notify.NewSoundNotifier(logger, cfg.NotificationSendAI, cfg.NotificationSendTTS)
+	notifier := notify.NewSoundNotifier(logger, ply, cfg.NotificationSendAI, cfg.NotificationSendTTS)
 
-	s := &Scheduler{cfg: cfg, req: req, speech: sp, tts: synth, player: p, notifier: notifier, logger: logger, cleaner: image.NewCleaner(logger), vts: vts}
-	s.logger.Infow("TTS selected", "service", service)
+	s := &Scheduler{req: req, speech: sp, tts: chain, player: ply, notifier: notifier, logger: logger, cleaner: image.NewCleaner(logger), vts: vts, events: events, service: service,
+		forceCh: make(chan struct{}, 1), sayCh: make(chan string, 8), pinned: -1}
+	s.conf.Store(cfg)
+	s.logger.Infow("TTS selected", "service", service, "chain", names)
 	return s
 }
 
+// cfg возвращает текущий конфиг (меняется через SetConfig).
+func (s *Scheduler) cfg() *config.Config { return s.conf.Load() }
+
+// SetConfig атомарно подменяет конфиг: промпты, персонажи и темп тиков применяются со следующего тика.
+// Закреплённый персонаж сохраняется, если он есть в новом списке.
+func (s *Scheduler) SetConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old := s.cfg(); s.pinned >= 0 && s.pinned < len(old.CharacterList) {
+		name := personaName(old.CharacterList[s.pinned], s.pinned)
+		s.pinned = -1
+		for i, item := range cfg.CharacterList {
+			if personaName(item, i) == name {
+				s.pinned = i
+				break
+			}
+		}
+	}
+	s.conf.Store(cfg)
+}
+
+// interval — базовый интервал между тиками.
+func (s *Scheduler) interval() time.Duration {
+	if d := time.Duration(s.cfg().TimerIntervalSeconds) * time.Second; d > 0 {
+		return d
+	}
+	return 10 * time.Second
+}
+
 // Run запускает бесконечный цикл до отмены контекста или достижения лимита ошибок.
 // Первый запуск выполняется по истечении первого интервала (initial delay = interval).
 func (s *Scheduler) Run(ctx context.Context) error {
-	base := time.Duration(s.cfg.TimerIntervalSeconds) * time.Second
-	if base <= 0 {
-		base = 10 * time.Second
-	}
+	base := s.interval()
 
-	// Фоновая задача очистки изображений по TTL
-	cleanInterval := base
-	ttl := time.Duration(s.cfg.ImagesTTLSeconds) * time.Second
+	// Фоновая задача очистки изображений по TTL; интервал и TTL перечитываются на каждом проходе —
+	// они меняются горячей перезагрузкой
 	stopClean := make(chan struct{})
 	go func() {
-		t := time.NewTicker(cleanInterval)
+		t := time.NewTimer(base)
 		defer t.Stop()
 		for {
 			select {
@@ -104,23 +183,34 @@ func (s *Scheduler) Run(ctx context.Context) error {
 				close(stopClean)
 				return
 			case <-t.C:
-				s.cleaner.Clean(s.cfg.ImagesSourceDir, ttl, s.cfg.DebugMode)
+				cfg := s.cfg()
+				s.cleaner.Clean(cfg.ImagesSourceDir, time.Duration(cfg.ImagesTTLSeconds)*time.Second, cfg.DebugMode)
+				t.Reset(s.interval())
 			}
 		}
 	}()
 
 	// Ждём первый интервал перед первой сработкой
-	s.logger.Infow("Scheduler started", "interval", base.String(), "overlap", s.cfg.OverlapPolicy)
+	s.logger.Infow("Scheduler started", "interval", base.String(), "overlap", s.cfg().OverlapPolicy)
 
 	// Основной цикл ожидания: базовый таймер И сигналы от Speech для раннего тика
+	immediate := false // после barge-in отвечаем стримеру без ожидания
 	for {
-		// Фиксированная задержка без джиттера
-		t := time.NewTimer(base)
+		// Фиксированная задержка без джиттера; интервал перечитываем — он меняется горячей перезагрузкой
+		t := time.NewTimer(s.interval())
+		trigger := triggerTimer
+		if immediate {
+			trigger = triggerBargeIn
+			t.Reset(0)
+			immediate = false
+		}
 		earlyCh := (<-chan struct{})(nil)
-		if s.speech != nil && s.cfg.EnableEarlyTick {
+		if s.speech != nil && s.cfg().EnableEarlyTick {
 			earlyCh = s.speech.NotifyCh()
 		}
 		firedEarly := false
+		forced := false
+		say := ""
 		select {
 		case <-ctx.Done():
 			if !t.Stop() {
@@ -136,6 +226,9 @@ func (s *Scheduler) Run(ctx context.Context) error {
 			// обычный тик по таймеру
 		case <-earlyCh:
 			firedEarly = true
+			if trigger == triggerTimer {
+				trigger = triggerEarly
+			}
 			if !t.Stop() {
 				// слить, если уже сработал
 				select {
@@ -143,17 +236,50 @@ func (s *Scheduler) Run(ctx context.Context) error {
 				default:
 				}
 			}
+		case <-s.forceCh:
+			forced = true
+			trigger = triggerForced
+			t.Stop()
+		case say = <-s.sayCh:
+			forced = true
+			trigger = triggerSay
+			t.Stop()
+		}
+		// На паузе пропускаем тики по таймеру и речи; внеочередные тики и озвучка выполняются
+		if s.paused.Load() && !forced {
+			metrics.TicksSkipped.WithLabelValues("paused").Inc()
+			continue
 		}
 
-		if err := s.runTick(ctx); err != nil {
+		err := s.runTick(ctx, trigger, say)
+		if ctx.Err() != nil {
+			// Остановка приложения: текущий тик уже договорил или отменён
+			s.stopPrev()
+			<-stopClean
+			return context.Cause(ctx)
+		}
+		s.mu.Lock()
+		s.lastErr = ""
+		if err != nil {
+			s.lastErr = err.Error()
+		}
+		s.mu.Unlock()
+		if errors.Is(err, errBargeIn) {
+			// Прерывание речью стримера — не ошибка: сразу запускаем новый тик с его репликой
+			s.logger.Infow("Tick interrupted by streamer speech; answering immediately")
+			immediate = true
+			continue
+		}
+		if err != nil {
 			s.consecutiveErrors++
+			s.events.Publish(bus.TypeError, 0, bus.Error{Source: "tick", Message: err.Error()})
 			if firedEarly {
 				s.logger.Errorw("Early tick failed", "error", err, "consecutiveErrors", s.consecutiveErrors)
 			} else {
 				s.logger.Errorw("Tick failed", "error", err, "consecutiveErrors", s.consecutiveErrors)
 			}
-			if s.consecutiveErrors >= max(1, s.cfg.MaxConsecutiveErrors) {
-				s.logger.Errorw("Stopping due to consecutive errors threshold", "threshold", s.cfg.MaxConsecutiveErrors)
+			if s.consecutiveErrors >= max(1, s.cfg().MaxConsecutiveErrors) {
+				s.logger.Errorw("Stopping due to consecutive errors threshold", "threshold", s.cfg().MaxConsecutiveErrors)
 				s.stopPrev()
 				return err
 			}
@@ -163,25 +289,38 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	}
 }
 
-func (s *Scheduler) runTick(parent context.Context) error {
+// runTick выполняет один тик: запрос к ИИ и озвучку ответа. Непустой say озвучивается без запроса к ИИ.
+func (s *Scheduler) runTick(parent context.Context, trigger, say string) (err error) {
+	// Снимок конфига на весь тик: горячая перезагрузка не меняет настройки посреди тика
+	cfg := s.cfg()
+
 	// Политика overlap
 	if s.running.Load() {
-		switch s.cfg.OverlapPolicy {
+		switch cfg.OverlapPolicy {
 		case overlapPreempt:
 			s.logger.Infow("Preempting previous tick")
+			metrics.TicksPreempted.WithLabelValues("overlap").Inc()
 			s.stopPrev()
 		default: // skip
 			s.logger.Infow("Skipping tick due to overlap")
+			metrics.TicksSkipped.WithLabelValues("overlap").Inc()
 			return nil
 		}
 	}
 
 	// Создаём контекст тика с тайм-аутом
-	timeout := time.Duration(s.cfg.TickTimeoutSeconds) * time.Second
+	timeout := time.Duration(cfg.TickTimeoutSeconds) * time.Second
 	if timeout <= 0 {
 		timeout = 30 * time.Second
 	}
-	tickCtx, cancel := context.WithTimeoutCause(parent, timeout, errors.New("tick timeout"))
+	// Отмену parent не наследуем напрямую: при остановке приложения даём договорить текущей речи (drainOnStop)
+	timeoutCtx, cancelTimeout := context.WithTimeoutCause(context.WithoutCancel(parent), timeout, errors.New("tick timeout"))
+	tickCtx, cancelCause := context.WithCancelCause(timeoutCtx)
+	go s.drainOnStop(parent, tickCtx, cancelCause)
+	cancel := func() {
+		cancelCause(context.Canceled)
+		cancelTimeout()
+	}
 
 	// Сохраняем cancel как текущий исполняемый тик и увеличиваем поколение
 	s.mu.Lock()
@@ -204,66 +343,158 @@ func (s *Scheduler) runTick(parent context.Context) error {
 	start := time.Now()
 	s.logger.Infow("Tick start")
 
-	// Выбор характера (если есть список)
+	// Выбор характера: закреплённый через control API или случайный
 	var characterItem *config.CharacterItem
 	var vtubeTags []string
-	n := len(s.cfg.CharacterList)
-	item := s.cfg.CharacterList[rand.Intn(n)]
+	n := len(cfg.CharacterList)
+	s.mu.Lock()
+	idx := s.pinned
+	if idx < 0 || idx >= n {
+		idx = rand.Intn(n)
+	}
+	item := cfg.CharacterList[idx]
+	s.persona = personaName(item, idx)
+	s.lastTick = start
+	s.mu.Unlock()
 	// фиксируем копию тегов для VTube
 	vtubeTags = append([]string(nil), item.Tags...)
 	characterItem = &item
 
+	// События тика для подписчиков: ID тика передаётся нижним слоям через контекст
+	tickCtx = bus.WithTick(tickCtx, localGen)
+	s.events.Publish(bus.TypeTickStart, localGen, bus.TickStart{Trigger: trigger, Persona: personaName(item, idx), Character: characterItem})
+	spoke := false
+	failReason := "" // источник ошибки тика для метрик: llm|tts|playback
+	defer func() {
+		end := bus.TickEnd{Status: "ok", DurationMs: time.Since(start).Milliseconds()}
+		switch {
+		case errors.Is(err, errBargeIn):
+			end.Status = "interrupted"
+			metrics.TicksPreempted.WithLabelValues("barge_in").Inc()
+		case err != nil:
+			end.Status = "error"
+			end.Error = err.Error()
+			metrics.TicksFailed.WithLabelValues(cmp.Or(failReason, "other")).Inc()
+		case !spoke:
+			end.Status = "empty"
+			metrics.TicksSkipped.WithLabelValues("no_input").Inc()
+		}
+		metrics.TickDuration.WithLabelValues(end.Status).Observe(time.Since(start).Seconds())
+		s.events.Publish(bus.TypeTickEnd, localGen, end)
+	}()
+
+	// Следим за речью стримера: при новой реплике прерываем тик (barge-in)
+	mode := s.bargeInMode(characterItem)
+	s.watchBargeIn(tickCtx, mode, cancelCause)
+
 	// Запрос через requester: формирование промпта и отправка
-	text, err := s.req.SendMessage(tickCtx, characterItem)
-	if err != nil {
-		return err
+	text := say
+	if text == "" {
+		if text, err = s.req.SendMessage(tickCtx, characterItem); err != nil {
+			failReason = "llm"
+			err = bargeInOr(tickCtx, err)
+			if !errors.Is(err, errBargeIn) {
+				s.events.Publish(bus.TypeError, localGen, bus.Error{Source: "llm", Message: err.Error()})
+			}
+			return err
+		}
 	}
 
 	// Проигрываем TTS, если есть ответ
 	if text != "" {
+		spoke = true
 		s.logger.Infow(text)
-		// Перед синтезом речи проигрываем уведомление TTS (не критично к ошибкам)
+		// Уведомление TTS звучит параллельно синтезу (не критично к ошибкам); речь под ним приглушается
 		if s.notifier != nil {
-			if err := s.notifier.PlayTTS(tickCtx); err != nil {
-				s.logger.Warnw("TTS notification sound failed", "error", err)
-			}
+			go func() {
+				if err := s.notifier.PlayTTS(tickCtx); err != nil && tickCtx.Err() == nil {
+					s.logger.Warnw("TTS notification sound failed", "error", err)
+				}
+			}()
 		}
-		// Выбор конфига под текущий сервис
-		var ttsCfg any
-		prompt := ""
-		switch strings.ToLower(strings.TrimSpace(s.cfg.TTSService)) {
-		case "yandex":
-			ttsCfg = s.cfg.YandexTTS
-		case "gemini", "google-gemini":
-			ttsCfg = s.cfg.GeminiTTS
-			prompt = characterItem.Text
-		default: // google
-			ttsCfg = s.cfg.GoogleTTS
+		// Теги разметки речи переводит цепочка TTS; подписчикам (оверлей, аудит) — текст без них
+		spoken := markup.Strip(text)
+		s.events.Publish(bus.TypeTTSStart, localGen, bus.TTSStart{Provider: s.service, Text: spoken})
+		// Длинный ответ синтезируем кусками параллельно: звук начинается, как только готов первый кусок
+		chunks := []string{text}
+		if cfg.TTSChunk.Enabled {
+			if c := chunk.Split(text, cfg.TTSChunk.MaxChars); len(c) > 0 {
+				chunks = c
+			}
 		}
-		format, rc, synErr := s.tts.Synthesize(tickCtx, text, prompt, ttsCfg)
-		if synErr != nil {
+		synthCtx, stopSynth := context.WithCancel(tickCtx)
+		defer stopSynth()
+		synthStart := time.Now()
+		// Промпт персонажа цепочка передаёт только провайдерам, которые его понимают (Capabilities.StylePrompt)
+		results := chunk.Synthesize(synthCtx, chunks, cfg.TTSChunk.Parallel, func(ctx context.Context, t, prefer string) (tts.Provider, string, io.ReadCloser, error) {
+			return s.tts.SynthesizeFrom(ctx, t, characterItem.Text, prefer)
+		})
+		first := <-results
+		synthMs := time.Since(synthStart).Milliseconds()
+		if synErr := first.Err; synErr != nil {
 			// Ошибка TTS трактуем как ошибку тика?
 			// По ТЗ: «TTS проигрывается при каждом тике, если был ответ» — ошибок TTS не указано отдельно,
 			// логируем и считаем ошибкой тика, чтобы не зациклиться в немом режиме.
-			return synErr
+			failReason = "tts"
+			err = bargeInOr(tickCtx, synErr)
+			if !errors.Is(err, errBargeIn) {
+				s.events.Publish(bus.TypeError, localGen, bus.Error{Source: "tts", Message: err.Error()})
+			}
+			return err
 		}
 		// До воспроизведения отправим эмоции в VTube по тегам
-		if s.vts != nil && len(vtubeTags) > 0 && s.cfg.VTube.Enabled {
+		if s.vts != nil && len(vtubeTags) > 0 && cfg.VTube.Enabled {
 			// Логируем список тегов перед отправкой — для диагностики несоответствий имён хоткеев
 			s.logger.Infow("VTS tags before trigger", "tags", vtubeTags)
+			ev := bus.VTubeTrigger{Tags: vtubeTags}
 			if err := s.vts.TriggerByNames(vtubeTags); err != nil {
 				s.logger.Warnw("VTS trigger before play failed", "error", err)
+				metrics.VTubeTriggerFailures.Inc()
+				ev.Error = err.Error()
 			}
+			s.events.Publish(bus.TypeVTubeTrigger, localGen, ev)
 		}
-		// Проигрываем звук
-		if err := s.player.Play(format, rc); err != nil {
-			return err
+		// Проигрываем куски подряд без пауз; при barge-in в режиме fade речь плавно затухает
+		opts := player.Options{Kind: player.KindSpeech}
+		if mode == bargeInFade {
+			opts.FadeOut = cfg.BargeInFade
 		}
+		clips := make(chan player.Clip)
+		go s.queueChunks(synthCtx, localGen, personaName(item, idx), first, results, clips, func(text string, d time.Duration) {
+			// Субтитры для оверлея публикуем в момент начала звука куска, с его длительностью
+			s.events.Publish(bus.TypeSubtitle, localGen, bus.Subtitle{
+				Text:       text,
+				Persona:    personaName(item, idx),
+				Emotion:    vtubeTags,
+				DurationMs: d.Milliseconds(),
+			})
+		})
+		playStart := time.Now()
+		s.playing.Store(true)
+		playErr := s.player.PlayQueue(tickCtx, clips, opts)
+		s.playing.Store(false)
+		stopSynth()
+		metrics.PlaybackDuration.Observe(time.Since(playStart).Seconds())
+		s.events.Publish(bus.TypeTTSEnd, localGen, bus.TTSEnd{
+			Provider:    first.Provider.Name,
+			Format:      first.Format,
+			SynthMs:     synthMs,
+			PlaybackMs:  time.Since(playStart).Milliseconds(),
+			Interrupted: playErr != nil,
+		})
 		// После воспроизведения — сброс эмоции
-		if s.vts != nil && s.cfg.VTube.Enabled {
+		if s.vts != nil && cfg.VTube.Enabled {
+			ev := bus.VTubeTrigger{Tags: []string{cfg.VTube.ResetEmotion}, Reset: true}
 			if err := s.vts.TriggerReset(); err != nil {
 				s.logger.Warnw("VTS reset after play failed", "error", err)
+				metrics.VTubeTriggerFailures.Inc()
+				ev.Error = err.Error()
 			}
+			s.events.Publish(bus.TypeVTubeTrigger, localGen, ev)
+		}
+		if playErr != nil {
+			failReason = "playback"
+			return bargeInOr(tickCtx, playErr)
 		}
 	}
 
@@ -271,6 +502,40 @@ func (s *Scheduler) runTick(parent context.Context) error {
 	return nil
 }
 
+// queueChunks передаёт синтезированные куски плееру по порядку и закрывает clips после последнего.
+// Кусок, который не синтезировал ни один провайдер, пропускается — остальная речь доигрывается.
+func (s *Scheduler) queueChunks(ctx context.Context, gen int64, persona string, r chunk.Result, results <-chan chunk.Result, clips chan<- player.Clip, onStart func(text string, d time.Duration)) {
+	defer close(clips)
+	for {
+		if r.Err != nil {
+			if ctx.Err() == nil {
+				s.logger.Warnw("TTS chunk skipped", "text", r.Text, "error", r.Err)
+				s.events.Publish(bus.TypeError, gen, bus.Error{Source: "tts", Message: r.Err.Error()})
+			}
+		} else {
+			text := markup.Strip(r.Text)
+			clip := player.Clip{
+				Format:  r.Format,
+				R:       r.RC,
+				GainDB:  r.Provider.GainDB,
+				Meta:    player.Meta{Text: text, Provider: r.Provider.Name, Persona: persona},
+				OnStart: func(d time.Duration) { onStart(text, d) },
+			}
+			select {
+			case clips <- clip:
+			case <-ctx.Done():
+				_ = r.RC.Close()
+				return
+			}
+		}
+		next, ok := <-results
+		if !ok {
+			return
+		}
+		r = next
+	}
+}
+
 func (s *Scheduler) stopPrev() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -279,3 +544,154 @@ func (s *Scheduler) stopPrev() {
 		s.cancelPrev = nil
 	}
 }
+
+// drainOnStop отменяет тик при остановке приложения. Если речь уже звучит — ждёт её окончания,
+// но не дольше ShutdownDrain; иначе (запрос к ИИ, синтез) отменяет сразу.
+func (s *Scheduler) drainOnStop(parent, tickCtx context.Context, cancel context.CancelCauseFunc) {
+	select {
+	case <-tickCtx.Done():
+		return
+	case <-parent.Done():
+	}
+	if s.playing.Load() && s.cfg().ShutdownDrain > 0 {
+		s.logger.Infow("Draining current speech before shutdown", "timeout", s.cfg().ShutdownDrain.String())
+		select {
+		case <-tickCtx.Done():
+			return
+		case <-time.After(s.cfg().ShutdownDrain):
+		}
+	}
+	cancel(context.Cause(parent))
+}
+
+// bargeInMode возвращает режим barge-in: настройка персонажа приоритетнее общей.
+func (s *Scheduler) bargeInMode(item *config.CharacterItem) string {
+	mode := s.cfg().BargeIn
+	if item != nil && strings.TrimSpace(item.BargeIn) != "" {
+		mode = item.BargeIn
+	}
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case bargeInStop, bargeInFade:
+		return m
+	default:
+		return bargeInOff
+	}
+}
+
+// watchBargeIn в фоне ждёт новую реплику стримера и прерывает тик отменой контекста (плеер сам глушит звук).
+// Горутина завершается вместе с контекстом тика.
+func (s *Scheduler) watchBargeIn(ctx context.Context, mode string, cancel context.CancelCauseFunc) {
+	if s.speech == nil || mode == bargeInOff {
+		return
+	}
+	// Сигнал, пришедший до старта тика, относится к уже попавшим в промпт репликам
+	select {
+	case <-s.speech.NotifyCh():
+	default:
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.speech.NotifyCh():
+				// Реплика уже забрана в текущий промпт — прерывать нечего
+				if s.speech.Len() == 0 {
+					continue
+				}
+				s.logger.Infow("Barge-in: streamer speaks, interrupting tick", "mode", mode)
+				cancel(errBargeIn)
+				return
+			}
+		}
+	}()
+}
+
+// bargeInOr возвращает errBargeIn, если тик отменён речью стримера, иначе исходную ошибку.
+func bargeInOr(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), errBargeIn) {
+		return errBargeIn
+	}
+	return err
+}
+
+// Pause приостанавливает тики по таймеру и речи.
+func (s *Scheduler) Pause() { s.paused.Store(true) }
+
+// Resume снимает паузу.
+func (s *Scheduler) Resume() { s.paused.Store(false) }
+
+// ForceTick запрашивает внеочередной тик (выполняется и на паузе).
+func (s *Scheduler) ForceTick() {
+	select {
+	case s.forceCh <- struct{}{}:
+	default: // тик уже запрошен
+	}
+}
+
+// Say ставит фразу в очередь на озвучку через текущий TTS и VTube без запроса к ИИ.
+func (s *Scheduler) Say(text string) error {
+	select {
+	case s.sayCh <- text:
+		return nil
+	default:
+		return ErrSayQueueFull
+	}
+}
+
+// Personas возвращает имена персонажей из CHARACTER_LIST.
+func (s *Scheduler) Personas() []string {
+	list := s.cfg().CharacterList
+	out := make([]string, 0, len(list))
+	for i, item := range list {
+		out = append(out, personaName(item, i))
+	}
+	return out
+}
+
+// SetPersona закрепляет персонажа по имени или индексу; пустое имя или "random" возвращает случайный выбор.
+func (s *Scheduler) SetPersona(name string) error {
+	name = strings.TrimSpace(name)
+	idx := -1
+	if name != "" && !strings.EqualFold(name, "random") {
+		for i, p := range s.Personas() {
+			if strings.EqualFold(p, name) || strconv.Itoa(i) == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return ErrUnknownPersona
+		}
+	}
+	s.mu.Lock()
+	s.pinned = idx
+	s.mu.Unlock()
+	return nil
+}
+
+// Status возвращает снимок состояния планировщика.
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Status{
+		Paused:     s.paused.Load(),
+		Running:    s.running.Load(),
+		TTSService: s.cfg().TTSService,
+		Persona:    s.persona,
+		LastTickAt: s.lastTick,
+		LastError:  s.lastErr,
+	}
+	if list := s.cfg().CharacterList; s.pinned >= 0 && s.pinned < len(list) {
+		st.PinnedPersona = personaName(list[s.pinned], s.pinned)
+	}
+	return st
+}
+
+// personaName — имя персонажа; без явного имени используется индекс в списке.
+func personaName(item config.CharacterItem, idx int) string {
+	if name := strings.TrimSpace(item.Name); name != "" {
+		return name
+	}
+	return "persona-" + strconv.Itoa(idx)
+}
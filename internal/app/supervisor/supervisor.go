@@ -0,0 +1,179 @@
+package supervisor
+
+import (
+	"OpenAIClient/internal/service/health"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Политика перезапуска компонента
+type Policy int
+
+const (
+	RestartNever     Policy = iota // завершение (с ошибкой или без) окончательно
+	RestartOnFailure               // перезапуск с backoff, если Run вернул ошибку
+)
+
+// Backoff перезапусков: удваивается от backoffMin до backoffMax; сбрасывается после стабильной работы
+const (
+	backoffMin    = time.Second
+	backoffMax    = 30 * time.Second
+	backoffStable = time.Minute
+)
+
+// Component — подсистема под надзором. Run блокирует до отмены ctx или сбоя.
+type Component struct {
+	Name     string
+	Run      func(ctx context.Context) error
+	Restart  Policy
+	Critical bool // завершение Run без перезапуска останавливает приложение
+
+	// StopTimeout — сколько ждать завершения Run после отмены; 0 — Config.StopTimeout
+	StopTimeout time.Duration
+}
+
+// Config — параметры супервизора.
+type Config struct {
+	StopTimeout time.Duration // срок остановки компонента по умолчанию
+}
+
+// ErrStopTimeout — компонент не завершился за отведённое время.
+var ErrStopTimeout = errors.New("component stop timeout")
+
+type entry struct {
+	Component
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// Supervisor запускает компоненты по порядку регистрации, перезапускает упавшие
+// и останавливает их в обратном порядке со сроками.
+type Supervisor struct {
+	cfg    Config
+	health *health.Registry
+	logger *zap.SugaredLogger
+
+	entries []*entry
+	failed  chan error // сбои критичных компонентов
+	once    sync.Once
+}
+
+func New(cfg Config, reg *health.Registry, logger *zap.SugaredLogger) *Supervisor {
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = 5 * time.Second
+	}
+	return &Supervisor{cfg: cfg, health: reg, logger: logger, failed: make(chan error, 1)}
+}
+
+// Add регистрирует компонент; порядок регистрации — порядок запуска.
+func (s *Supervisor) Add(c Component) {
+	if c.StopTimeout <= 0 {
+		c.StopTimeout = s.cfg.StopTimeout
+	}
+	s.entries = append(s.entries, &entry{Component: c})
+}
+
+// Run запускает компоненты и ждёт отмены ctx или сбоя критичного компонента,
+// затем останавливает всё в обратном порядке. Возвращает ошибку критичного компонента.
+func (s *Supervisor) Run(ctx context.Context) error {
+	for _, e := range s.entries {
+		// Контекст компонента не наследует отмену ctx: останавливаем компоненты сами, по порядку
+		cctx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+		e.cancel = cancel
+		e.done = make(chan struct{})
+		go s.supervise(cctx, e)
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		s.logger.Infow("Shutting down", "reason", context.Cause(ctx))
+	case err = <-s.failed:
+		s.logger.Errorw("Critical component failed; shutting down", "error", err)
+	}
+	s.shutdown()
+	return err
+}
+
+// shutdown останавливает компоненты в обратном порядке, каждый со своим сроком.
+func (s *Supervisor) shutdown() {
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		e := s.entries[i]
+		e.cancel(context.Canceled)
+		select {
+		case <-e.done:
+			s.logger.Infow("Component stopped", "component", e.Name)
+		case <-time.After(e.StopTimeout):
+			s.logger.Warnw("Component did not stop in time", "component", e.Name, "timeout", e.StopTimeout.String())
+			s.health.Set(e.Name, health.StateDown, ErrStopTimeout.Error())
+		}
+	}
+}
+
+// supervise выполняет Run компонента с политикой перезапуска.
+func (s *Supervisor) supervise(ctx context.Context, e *entry) {
+	defer close(e.done)
+	backoff := backoffMin
+	for {
+		s.health.Set(e.Name, health.StateOK, "")
+		started := time.Now()
+		err := s.run(ctx, e)
+		if ctx.Err() != nil {
+			s.health.Set(e.Name, health.StateDown, "stopped")
+			return
+		}
+		if err == nil || e.Restart == RestartNever {
+			if err != nil {
+				s.logger.Errorw("Component failed", "component", e.Name, "error", err)
+				s.health.Set(e.Name, health.StateDown, err.Error())
+			} else {
+				s.logger.Infow("Component finished", "component", e.Name)
+				s.health.Set(e.Name, health.StateDown, "finished")
+			}
+			if e.Critical {
+				s.fail(fmt.Errorf("%s: %w", e.Name, cmpErr(err)))
+			}
+			return
+		}
+
+		if time.Since(started) >= backoffStable {
+			backoff = backoffMin
+		}
+		s.logger.Warnw("Component failed; restarting", "component", e.Name, "error", err, "backoff", backoff.String())
+		s.health.Set(e.Name, health.StateDegraded, err.Error())
+		select {
+		case <-ctx.Done():
+			s.health.Set(e.Name, health.StateDown, "stopped")
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, backoffMax)
+	}
+}
+
+// run вызывает Run компонента, превращая панику в ошибку.
+func (s *Supervisor) run(ctx context.Context, e *entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return e.Run(ctx)
+}
+
+func (s *Supervisor) fail(err error) {
+	s.once.Do(func() { s.failed <- err })
+}
+
+// cmpErr подставляет причину для критичного компонента, завершившегося без ошибки.
+func cmpErr(err error) error {
+	if err == nil {
+		return errors.New("finished unexpectedly")
+	}
+	return err
+}
@@ -1,138 +1,235 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"encoding/json"
 
-	"github.com/caarlos0/env/v6"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DebugMode           bool            `env:"DEBUG_MODE"`          //Режим дебага
-	AssistantPrompt     string          `env:"ASSISTANT_PROMPT"`    //Текст промпта ассистента диалога
-	AssistantSentences  int             `env:"ASSISTANT_SENTENCES"` // Количество предложений в ответе ассистента
-	CharacterList       []CharacterItem // Обрабатывается методом LoadCharacterListFromEnv из .env переменной CHARACTER_LIST
-	SpeechPrompt        []string        `env:"SPEECH_PROMPT" envSeparator:";"` // Список фиксированных сообщений для каждого тика; выбирается случайно
-	ImagesSourceDir     string          `env:"IMAGES_SOURCE_DIR"`              // Папка с исходными изображениями
-	ImagesToPick        int             `env:"IMAGES_TO_PICK"`                 // Сколько последних изображений брать
-	ImagesTTLSeconds    int             `env:"IMAGES_TTL_SECONDS"`             // Время, через которое картинки считаются старыми и их надо удалить, в секундах
-	HistoryHeader       string          `env:"HISTORY_HEADER"`                 // Заголовок блока с историей ответов ИИ
-	MaxHistoryRecords   int             `env:"MAX_HISTORY_RECORDS"`            // Максимум хранимых ответов ИИ в локальной истории
-	NotificationSendAI  string          `env:"NOTIFICATION_SEND_AI"`           // Путь к звуку уведомления ИИ (получено сообщение)
-	NotificationSendTTS string          `env:"NOTIFICATION_SEND_TTS"`          // Путь к звуку перед TTS (озвучка ответа)
+	DebugMode           bool            `env:"DEBUG_MODE" yaml:"debug_mode"`                        //Режим дебага
+	ConfigWatch         bool            `env:"CONFIG_WATCH" yaml:"config_watch"`                    // Перечитывать .env при изменении (горячая перезагрузка промптов и темпа)
+	AssistantPrompt     string          `env:"ASSISTANT_PROMPT" yaml:"assistant_prompt"`            //Текст промпта ассистента диалога
+	AssistantSentences  int             `env:"ASSISTANT_SENTENCES" yaml:"assistant_sentences"`      // Количество предложений в ответе ассистента
+	CharacterList       []CharacterItem `yaml:"character_list"`                                     // Обрабатывается методом LoadCharacterListFromEnv из .env переменной CHARACTER_LIST
+	SpeechPrompt        []string        `env:"SPEECH_PROMPT" envSeparator:";" yaml:"speech_prompt"` // Список фиксированных сообщений для каждого тика; выбирается случайно
+	ImagesSourceDir     string          `env:"IMAGES_SOURCE_DIR" yaml:"images_source_dir"`          // Папка с исходными изображениями
+	ImagesToPick        int             `env:"IMAGES_TO_PICK" yaml:"images_to_pick"`                // Сколько последних изображений брать
+	ImagesTTLSeconds    int             `env:"IMAGES_TTL_SECONDS" yaml:"images_ttl_seconds"`        // Время, через которое картинки считаются старыми и их надо удалить, в секундах
+	HistoryHeader       string          `env:"HISTORY_HEADER" yaml:"history_header"`                // Заголовок блока с историей ответов ИИ
+	MaxHistoryRecords   int             `env:"MAX_HISTORY_RECORDS" yaml:"max_history_records"`      // Максимум хранимых ответов ИИ в локальной истории
+	NotificationSendAI  string          `env:"NOTIFICATION_SEND_AI" yaml:"notification_send_ai"`    // Путь к звуку уведомления ИИ (получено сообщение)
+	NotificationSendTTS string          `env:"NOTIFICATION_SEND_TTS" yaml:"notification_send_tts"`  // Путь к звуку перед TTS (озвучка ответа)
+	// Аудиовыход (общий микшер)
+	AudioSampleRate int     `env:"AUDIO_SAMPLE_RATE" yaml:"audio_sample_rate"` // Частота выхода, Гц; клипы ресемплируются к ней
+	AudioDuckDB     float64 `env:"AUDIO_DUCK_DB" yaml:"audio_duck_db"`         // Приглушение речи на время звуков уведомлений, dB
+	// Куда идёт звук: speaker|files|session|discard, несколько через запятую (speaker,session)
+	AudioSinks     []string            `env:"AUDIO_SINKS" envSeparator:"," yaml:"audio_sinks"`
+	AudioRecordDir string              `env:"AUDIO_RECORD_DIR" yaml:"audio_record_dir"` // Папка записей для files и session
+	AudioLoudness  AudioLoudnessConfig `yaml:"audio_loudness"`                          // Выравнивание громкости речи и уведомлений
 	// Скриншоттер
-	ScreenshotIntervalSeconds int `env:"SCREENSHOT_INTERVAL_SECONDS"` // Периодичность снятия скриншотов всего экрана, в секундах
+	ScreenshotIntervalSeconds int `env:"SCREENSHOT_INTERVAL_SECONDS" yaml:"screenshot_interval_seconds"` // Периодичность снятия скриншотов всего экрана, в секундах
 	// Общий переключатель сервиса TTS и конфиг Google/Gemini TTS
-	TTSService string `env:"TTS_SERVICE"` // yandex|google|gemini, по умолчанию google
-	GoogleTTS  GoogleTTSConfig
-	GeminiTTS  GeminiTTSConfig
-	YandexTTS  YandexTTSConfig
+	TTSService string `env:"TTS_SERVICE" yaml:"tts_service"` // yandex|google|gemini, по умолчанию google
+	// Цепочка провайдеров по порядку (gemini,google,yandex); пусто — только TTS_SERVICE
+	TTSProviders        []string           `env:"TTS_PROVIDERS" envSeparator:"," yaml:"tts_providers"`
+	TTSFallbackCooldown time.Duration      `env:"TTS_FALLBACK_COOLDOWN" yaml:"tts_fallback_cooldown"` // Сколько пропускать провайдера после ошибки
+	TTSMarkup           bool               `env:"TTS_MARKUP" yaml:"tts_markup"`                       // Подсказать модели теги разметки речи ([pause], [whisper]...)
+	GoogleTTS           GoogleTTSConfig    `yaml:"google_tts"`
+	GeminiTTS           GeminiTTSConfig    `yaml:"gemini_tts"`
+	YandexTTS           YandexTTSConfig    `yaml:"yandex_tts"`
+	TTSCache            TTSCacheConfig     `yaml:"tts_cache"`     // Дисковый кэш синтезированных фраз
+	TTSNormalize        TTSNormalizeConfig `yaml:"tts_normalize"` // Нормализация текста и словарь произношения
+	TTSChunk            TTSChunkConfig     `yaml:"tts_chunk"`     // Синтез длинных ответов кусками параллельно
 
 	// Настройки таймера (Scheduler)
-	TimerIntervalSeconds int    `env:"TIMER_INTERVAL_SECONDS"` // Базовый интервал между тиками
-	TickTimeoutSeconds   int    `env:"TICK_TIMEOUT_SECONDS"`   // Таймаут одного тика
-	OverlapPolicy        string `env:"OVERLAP_POLICY"`         // Политика при наложении: skip|preempt
-	MaxConsecutiveErrors int    `env:"MAX_CONSECUTIVE_ERRORS"` // Сколько ошибок подряд до остановки приложения
+	TimerIntervalSeconds int    `env:"TIMER_INTERVAL_SECONDS" yaml:"timer_interval_seconds"` // Базовый интервал между тиками
+	TickTimeoutSeconds   int    `env:"TICK_TIMEOUT_SECONDS" yaml:"tick_timeout_seconds"`     // Таймаут одного тика
+	OverlapPolicy        string `env:"OVERLAP_POLICY" yaml:"overlap_policy"`                 // Политика при наложении: skip|preempt
+	MaxConsecutiveErrors int    `env:"MAX_CONSECUTIVE_ERRORS" yaml:"max_consecutive_errors"` // Сколько ошибок подряд до остановки приложения
+
+	// Завершение работы (Ctrl+C)
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" yaml:"shutdown_timeout"` // Срок остановки одного компонента
+	ShutdownDrain   time.Duration `env:"SHUTDOWN_DRAIN" yaml:"shutdown_drain"`     // Сколько дать договорить текущей речи перед выходом
 
 	// STT (Handy) и Speech
-	STTHandyWindow       time.Duration `env:"STT_HANDY_WINDOW"`       // Окно совпадения буфера и хоткея
-	STTHotkeyDelay       time.Duration `env:"STT_HOTKEY_DELAY"`       // Задержка реакции на Ctrl+Enter
-	SpeechDefaultEnabled bool          `env:"SPEECH_DEFAULT_ENABLED"` // Включать speech-заголовок и дефолтный speech-prompt
-	SpeechHeader         string        `env:"SPEECH_HEADER"`          // Заголовок для блока сообщений из речи
-	SpeechMax            int           `env:"SPEECH_MAX"`             // Максимум хранимых сообщений речи
-	EnableEarlyTick      bool          `env:"ENABLE_EARLY_TICK"`      // Запускать тик ранее при наличии сообщений речи
+	STTHandyWindow       time.Duration `env:"STT_HANDY_WINDOW" yaml:"stt_handy_window"`             // Окно совпадения буфера и хоткея
+	STTHotkeyDelay       time.Duration `env:"STT_HOTKEY_DELAY" yaml:"stt_hotkey_delay"`             // Задержка реакции на Ctrl+Enter
+	SpeechDefaultEnabled bool          `env:"SPEECH_DEFAULT_ENABLED" yaml:"speech_default_enabled"` // Включать speech-заголовок и дефолтный speech-prompt
+	SpeechHeader         string        `env:"SPEECH_HEADER" yaml:"speech_header"`                   // Заголовок для блока сообщений из речи
+	SpeechMax            int           `env:"SPEECH_MAX" yaml:"speech_max"`                         // Максимум хранимых сообщений речи
+	EnableEarlyTick      bool          `env:"ENABLE_EARLY_TICK" yaml:"enable_early_tick"`           // Запускать тик ранее при наличии сообщений речи
+	BargeIn              string        `env:"BARGE_IN" yaml:"barge_in"`                             // Реакция на речь стримера во время тика: off|stop|fade
+	BargeInFade          time.Duration `env:"BARGE_IN_FADE" yaml:"barge_in_fade"`                   // Длительность затухания звука для BARGE_IN=fade
 
 	// Chat / Twitch
-	ChatHistoryHeader string `env:"CHAT_HISTORY_HEADER"` // Заголовок блока сообщений из чата
-	ChatMax           int    `env:"CHAT_MAX"`            // Максимум хранимых сообщений чата
-	TwitchUsername    string `env:"TWITCH_USERNAME"`     // Имя пользователя Twitch (логин)
-	TwitchOAuthToken  string `env:"TWITCH_OAUTH_TOKEN"`  // OAuth токен Twitch (может быть без префикса oauth:)
-	TwitchChannel     string `env:"TWITCH_CHANNEL"`      // Канал Twitch (один), без #
+	ChatHistoryHeader string `env:"CHAT_HISTORY_HEADER" yaml:"chat_history_header"` // Заголовок блока сообщений из чата
+	ChatMax           int    `env:"CHAT_MAX" yaml:"chat_max"`                       // Максимум хранимых сообщений чата
+	TwitchUsername    string `env:"TWITCH_USERNAME" yaml:"twitch_username"`         // Имя пользователя Twitch (логин)
+	TwitchOAuthToken  string `env:"TWITCH_OAUTH_TOKEN" yaml:"twitch_oauth_token"`   // OAuth токен Twitch (может быть без префикса oauth:)
+	TwitchChannel     string `env:"TWITCH_CHANNEL" yaml:"twitch_channel"`           // Канал Twitch (один), без #
 
 	// StateServer — приёмник игрового состояния (например, Dota GSI)
-	StateServer StateServerConfig
+	StateServer StateServerConfig `yaml:"state_server"`
 
 	// State — буфер последних сообщений из игрового состояния
-	StateHeader string `env:"STATE_HEADER"` // Заголовок для блока State
-	StateMax    int    `env:"STATE_MAX"`    // Максимум хранимых сообщений State
+	StateHeader string `env:"STATE_HEADER" yaml:"state_header"` // Заголовок для блока State
+	StateMax    int    `env:"STATE_MAX" yaml:"state_max"`       // Максимум хранимых сообщений State
 
 	// Screenshotter — включение/выключение фоновой съёмки скриншотов
-	ScreenshotEnabled bool `env:"SCREENSHOT_ENABLED"` // По умолчанию включён
+	ScreenshotEnabled bool `env:"SCREENSHOT_ENABLED" yaml:"screenshot_enabled"` // По умолчанию включён
 
 	// VTube Studio — конфигурация API клиента
-	VTube VTubeConfig
+	VTube VTubeConfig `yaml:"vtube"`
 	// VTube Studio — API ключ (Authentication Token), полученный ранее через AuthenticationTokenRequest
-	VTubeAPIKey string `env:"VTUBE_API_KEY"`
+	VTubeAPIKey string `env:"VTUBE_API_KEY" yaml:"vtube_api_key"`
+
+	// ControlAPI — локальный HTTP API управления компаньоном (Stream Deck, скрипты)
+	ControlAPI ControlAPIConfig `yaml:"control_api"`
+
+	// Audit — структурированный журнал тиков (JSONL) для replay и оценки
+	Audit AuditConfig `yaml:"audit"`
+
+	// Sections — блоки настроек, объявленные другими пакетами (RegisterSection): ключ YAML → указатель на структуру
+	Sections map[string]any `yaml:"-"`
 }
 
-// CharacterItem элемент из CHARACTER_LIST: текст и (пока не используемые) теги
+// CharacterItem элемент из CHARACTER_LIST: текст, теги эмоций VTube и настройки персонажа
 type CharacterItem struct {
-	Tags []string `json:"tags"`
-	Text string   `json:"text"`
+	Name    string   `json:"name,omitempty" yaml:"name"` // Имя персонажа для переключения через control API
+	Tags    []string `json:"tags" yaml:"tags"`
+	Text    string   `json:"text" yaml:"text"`
+	BargeIn string   `json:"barge_in,omitempty" yaml:"barge_in"` // off|stop|fade; пусто — берём общий BARGE_IN
 }
 
 // YandexTTSConfig конфигурация для синтеза речи через Yandex SpeechKit.
 type YandexTTSConfig struct {
-	APIKey  string `env:"YC_TTS_API_KEY"` // Ключ берём из .env/ENV. Если пуст — при использовании будет ошибка
-	Voice   string `env:"YC_TTS_VOICE"`   // Голос, по умолчанию filipp
-	Format  string `env:"YC_TTS_FORMAT"`  // mp3|wav, по умолчанию mp3
-	Speed   string `env:"YC_TTS_SPEED"`   // Скорость синтеза (1.0 по умолчанию в API); 1.3 = ~30% быстрее
-	Emotion string `env:"YC_TTS_EMOTION"` // Эмоциональная окраска: neutral|good|evil. По умолчанию evil
-	Volume  int    `env:"YC_TTS_VOLUME"`  // Громкость 0-100; 100 — не изменять громкость todo вероятно есть баг, что громкость уменьшается слишком быстро
+	APIKey  string `env:"YC_TTS_API_KEY" yaml:"api_key"` // Ключ берём из .env/ENV. Если пуст — при использовании будет ошибка
+	Voice   string `env:"YC_TTS_VOICE" yaml:"voice"`     // Голос, по умолчанию filipp
+	Format  string `env:"YC_TTS_FORMAT" yaml:"format"`   // mp3|wav|oggopus, по умолчанию mp3
+	Speed   string `env:"YC_TTS_SPEED" yaml:"speed"`     // Скорость синтеза (1.0 по умолчанию в API); 1.3 = ~30% быстрее
+	Emotion string `env:"YC_TTS_EMOTION" yaml:"emotion"` // Эмоциональная окраска: neutral|good|evil. По умолчанию evil
+	Volume  int    `env:"YC_TTS_VOLUME" yaml:"volume"`   // Громкость 0-100 (100 — не изменять, 50 — -10 dB); только при AUDIO_LOUDNESS_ENABLED=false
 }
 
 // VTubeConfig — конфигурация интеграции с VTube Studio Public API
 type VTubeConfig struct {
-	Enabled         bool   `env:"VTUBE_ENABLED"`
-	WSURL           string `env:"VTUBE_WS_URL"` // ws://localhost:8001
-	PluginName      string `env:"VTUBE_PLUGIN_NAME"`
-	PluginDeveloper string `env:"VTUBE_PLUGIN_DEV"`
-	APIVersion      string `env:"VTUBE_API_VERSION"`
-	ResetEmotion    string `env:"VTUBE_RESET_EMOTION"` // имя хоткея для сброса эмоций
+	Enabled         bool          `env:"VTUBE_ENABLED" yaml:"enabled"`
+	WSURL           string        `env:"VTUBE_WS_URL" yaml:"ws_url"` // ws://localhost:8001
+	PluginName      string        `env:"VTUBE_PLUGIN_NAME" yaml:"plugin_name"`
+	PluginDeveloper string        `env:"VTUBE_PLUGIN_DEV" yaml:"plugin_developer"`
+	APIVersion      string        `env:"VTUBE_API_VERSION" yaml:"api_version"`
+	ResetEmotion    string        `env:"VTUBE_RESET_EMOTION" yaml:"reset_emotion"` // имя хоткея для сброса эмоций
+	LipSync         LipSyncConfig `yaml:"lipsync"`
+}
+
+// LipSyncConfig — встроенный липсинк: рот аватара двигается по огибающей звучащей речи
+// (InjectParameterDataRequest), без виртуальных аудиокабелей.
+type LipSyncConfig struct {
+	Enabled    bool    `env:"VTUBE_LIPSYNC" yaml:"enabled"`
+	FPS        int     `env:"VTUBE_LIPSYNC_FPS" yaml:"fps"`                 // Частота отправки параметров
+	Gain       float64 `env:"VTUBE_LIPSYNC_GAIN" yaml:"gain"`               // Чувствительность: >1 — рот открывается шире
+	OpenParam  string  `env:"VTUBE_LIPSYNC_OPEN_PARAM" yaml:"open_param"`   // Параметр открытия рта
+	SmileParam string  `env:"VTUBE_LIPSYNC_SMILE_PARAM" yaml:"smile_param"` // Параметр формы рта; пусто — не управлять
 }
 
 // GoogleTTSConfig — конфигурация для синтеза речи через Google Cloud Text-to-Speech.
 type GoogleTTSConfig struct {
 	// Путь к файлу ключа сервисного аккаунта (ENV GOOGLE_APPLICATION_CREDENTIALS).
-	CredentialsPath string  `env:"GOOGLE_APPLICATION_CREDENTIALS"`
-	Language        string  `env:"GOOGLE_TTS_LANGUAGE"`
-	Voice           string  `env:"GOOGLE_TTS_VOICE"`
-	SpeakingRate    float64 `env:"GOOGLE_TTS_SPEAKING_RATE"`
-	Pitch           float64 `env:"GOOGLE_TTS_PITCH"`
-	VolumeGainDb    float64 `env:"GOOGLE_TTS_VOLUME_DB"`
+	CredentialsPath string  `env:"GOOGLE_APPLICATION_CREDENTIALS" yaml:"credentials_path"`
+	Language        string  `env:"GOOGLE_TTS_LANGUAGE" yaml:"language"`
+	Voice           string  `env:"GOOGLE_TTS_VOICE" yaml:"voice"`
+	SpeakingRate    float64 `env:"GOOGLE_TTS_SPEAKING_RATE" yaml:"speaking_rate"`
+	Pitch           float64 `env:"GOOGLE_TTS_PITCH" yaml:"pitch"`
+	VolumeGainDb    float64 `env:"GOOGLE_TTS_VOLUME_DB" yaml:"volume_gain_db"`
 	// Эффект профиля устройства воспроизведения
-	EffectsProfileID string `env:"GOOGLE_TTS_EFFECTS_PROFILE_ID"`
+	EffectsProfileID string `env:"GOOGLE_TTS_EFFECTS_PROFILE_ID" yaml:"effects_profile_id"`
 	// Тип входа: text|ssml (auto при отсутствии явного выбора)
-	InputType string `env:"GOOGLE_TTS_INPUT_TYPE"`
+	InputType string `env:"GOOGLE_TTS_INPUT_TYPE" yaml:"input_type"`
 }
 
 // GeminiTTSConfig — конфигурация для синтеза речи через Cloud Text-to-Speech (Gemini-TTS).
 type GeminiTTSConfig struct {
 	// Модель голоса Gemini‑TTS
-	ModelName        string  `env:"GEMINI_TTS_MODEL_NAME"`
-	Language         string  `env:"GEMINI_TTS_LANGUAGE"`
-	VoiceName        string  `env:"GEMINI_TTS_VOICE_NAME"`
-	SpeakingRate     float64 `env:"GEMINI_TTS_SPEAKING_RATE"`
-	Pitch            float64 `env:"GEMINI_TTS_PITCH"`
-	VolumeGainDb     float64 `env:"GEMINI_TTS_VOLUME_DB"`
-	EffectsProfileID string  `env:"GEMINI_TTS_EFFECTS_PROFILE_ID"`
+	ModelName        string  `env:"GEMINI_TTS_MODEL_NAME" yaml:"model_name"`
+	Language         string  `env:"GEMINI_TTS_LANGUAGE" yaml:"language"`
+	VoiceName        string  `env:"GEMINI_TTS_VOICE_NAME" yaml:"voice_name"`
+	SpeakingRate     float64 `env:"GEMINI_TTS_SPEAKING_RATE" yaml:"speaking_rate"`
+	Pitch            float64 `env:"GEMINI_TTS_PITCH" yaml:"pitch"`
+	VolumeGainDb     float64 `env:"GEMINI_TTS_VOLUME_DB" yaml:"volume_gain_db"`
+	EffectsProfileID string  `env:"GEMINI_TTS_EFFECTS_PROFILE_ID" yaml:"effects_profile_id"`
 	// Тип входа: text|ssml|prompt
-	InputType string `env:"GEMINI_TTS_INPUT_TYPE"`
-	Endpoint  string `env:"GEMINI_TTS_ENDPOINT"`
-	Prompt    string `env:"GEMINI_TTS_PROMPT"`
+	InputType string `env:"GEMINI_TTS_INPUT_TYPE" yaml:"input_type"`
+	Endpoint  string `env:"GEMINI_TTS_ENDPOINT" yaml:"endpoint"`
+	Prompt    string `env:"GEMINI_TTS_PROMPT" yaml:"prompt"`
 }
 
 // StateServerConfig — конфигурация сервиса приёма игрового состояния.
 type StateServerConfig struct {
-	Enabled   bool   `env:"STATE_SERVER_ENABLED"`    // Главный флаг включения/выключения
-	BindAddr  string `env:"STATE_SERVER_BIND_ADDR"`  // Адрес слушателя, напр. 127.0.0.1:3000
-	Path      string `env:"STATE_SERVER_PATH"`       // HTTP‑путь, напр. "/"
-	AuthToken string `env:"STATE_SERVER_AUTH_TOKEN"` // Токен авторизации (опционально)
+	Enabled   bool   `env:"STATE_SERVER_ENABLED" yaml:"enabled"`       // Главный флаг включения/выключения
+	BindAddr  string `env:"STATE_SERVER_BIND_ADDR" yaml:"bind_addr"`   // Адрес слушателя, напр. 127.0.0.1:3000
+	Path      string `env:"STATE_SERVER_PATH" yaml:"path"`             // HTTP‑путь, напр. "/"
+	AuthToken string `env:"STATE_SERVER_AUTH_TOKEN" yaml:"auth_token"` // Токен авторизации (опционально)
+}
+
+// ControlAPIConfig — конфигурация локального HTTP API управления.
+type ControlAPIConfig struct {
+	Enabled   bool   `env:"CONTROL_API_ENABLED" yaml:"enabled"`       // Включение API
+	BindAddr  string `env:"CONTROL_API_BIND_ADDR" yaml:"bind_addr"`   // Адрес слушателя, напр. 127.0.0.1:3100
+	AuthToken string `env:"CONTROL_API_AUTH_TOKEN" yaml:"auth_token"` // Bearer-токен; пусто — генерируется при запуске и пишется в лог
+
+	Dashboard      bool `env:"CONTROL_API_DASHBOARD" yaml:"dashboard"`             // Веб-панель оператора на том же адресе
+	DashboardTicks int  `env:"CONTROL_API_DASHBOARD_TICKS" yaml:"dashboard_ticks"` // Сколько последних тиков хранит панель
+
+	Overlay    bool   `env:"CONTROL_API_OVERLAY" yaml:"overlay"`         // Оверлей субтитров для OBS (browser source)
+	OverlayCSS string `env:"CONTROL_API_OVERLAY_CSS" yaml:"overlay_css"` // Путь к своему CSS оверлея; пусто — встроенный
+
+	Metrics bool `env:"CONTROL_API_METRICS" yaml:"metrics"` // Метрики Prometheus на /metrics
+}
+
+// AuditConfig — журнал тиков: одна JSON-строка на тик, ротация по размеру и по дням.
+type AuditConfig struct {
+	Enabled   bool   `env:"AUDIT_ENABLED" yaml:"enabled"`         // Включение журнала
+	Dir       string `env:"AUDIT_DIR" yaml:"dir"`                 // Папка файлов audit-YYYY-MM-DD.jsonl
+	MaxSizeMB int    `env:"AUDIT_MAX_SIZE_MB" yaml:"max_size_mb"` // Размер файла до ротации внутри дня
+}
+
+// TTSCacheConfig — кэш синтезированной речи на диске: повторные фразы не синтезируются заново.
+type TTSCacheConfig struct {
+	Enabled   bool          `env:"TTS_CACHE_ENABLED" yaml:"enabled"`
+	Dir       string        `env:"TTS_CACHE_DIR" yaml:"dir"`                 // Папка файлов кэша
+	MaxSizeMB int           `env:"TTS_CACHE_MAX_SIZE_MB" yaml:"max_size_mb"` // Лимит размера; сверх него вытесняются давно не использованные фразы
+	TTL       time.Duration `env:"TTS_CACHE_TTL" yaml:"ttl"`                 // Срок жизни записи; 0 — без срока
+}
+
+// TTSNormalizeConfig — подготовка текста к синтезу: числа и единицы словами, аббревиатуры по буквам, словарь произношения.
+type TTSNormalizeConfig struct {
+	Enabled    bool   `env:"TTS_NORMALIZE_ENABLED" yaml:"enabled"`
+	Dictionary string `env:"TTS_DICTIONARY" yaml:"dictionary"` // YAML-словарь произношения; правки подхватываются без перезапуска
+}
+
+// AudioLoudnessConfig — выравнивание громкости каждого клипа (речь любого провайдера, уведомления)
+// к целевой громкости по ITU-R BS.1770 с ограничителем пиков.
+type AudioLoudnessConfig struct {
+	Enabled    bool    `env:"AUDIO_LOUDNESS_ENABLED" yaml:"enabled"`
+	TargetLUFS float64 `env:"AUDIO_LOUDNESS_TARGET" yaml:"target"`           // Целевая громкость, LUFS (-16 — типично для речи)
+	MaxGainDB  float64 `env:"AUDIO_LOUDNESS_MAX_GAIN_DB" yaml:"max_gain_db"` // Предел усиления тихих клипов, dB
+	CeilingDB  float64 `env:"AUDIO_LIMITER_CEILING_DB" yaml:"ceiling_db"`    // Потолок пиков после усиления, dBFS
+}
+
+// TTSChunkConfig — синтез длинного ответа кусками по предложениям: куски синтезируются параллельно,
+// а звучат по порядку без пауз; воспроизведение начинается, как только готов первый.
+type TTSChunkConfig struct {
+	Enabled  bool `env:"TTS_CHUNK_ENABLED" yaml:"enabled"`
+	MaxChars int  `env:"TTS_CHUNK_MAX_CHARS" yaml:"max_chars"` // Предел длины куска; длинное предложение режется по запятым
+	Parallel int  `env:"TTS_CHUNK_PARALLEL" yaml:"parallel"`   // Сколько кусков синтезировать одновременно
 }
 
 // Defaults возвращает конфигурацию со значениями по умолчанию.
@@ -140,6 +237,7 @@ type StateServerConfig struct {
 func Defaults() *Config {
 	return &Config{
 		DebugMode:                 false,
+		ConfigWatch:               false,
 		AssistantPrompt:           "Ты помощник капитана и озвучиваешь то, что видишь на картинках",
 		AssistantSentences:        3,
 		CharacterList:             []CharacterItem{{Text: ""}},
@@ -157,19 +255,34 @@ func Defaults() *Config {
 		TickTimeoutSeconds:   120,
 		OverlapPolicy:        "skip", //`skip`|`preempt`
 		MaxConsecutiveErrors: 3,
+		ShutdownTimeout:      5 * time.Second,
+		ShutdownDrain:        10 * time.Second,
 		NotificationSendAI:   "sound/notification3.mp3",
 		NotificationSendTTS:  "sound/notification3.mp3",
+		AudioSampleRate:      48000,
+		AudioDuckDB:          -12,
+		AudioSinks:           []string{"speaker"},
+		AudioRecordDir:       "recordings",
+		AudioLoudness: AudioLoudnessConfig{
+			Enabled:    false, // включается явно: с ним YC_TTS_VOLUME и VolumeGainDb провайдеров перестают менять уровень речи
+			TargetLUFS: -16,
+			MaxGainDB:  12,
+			CeilingDB:  -1,
+		},
 		// STT/Speech
 		STTHandyWindow:       time.Second,
 		STTHotkeyDelay:       100 * time.Millisecond,
 		SpeechDefaultEnabled: true,
 		SpeechMax:            10,
 		EnableEarlyTick:      true,
+		BargeIn:              "off",
+		BargeInFade:          300 * time.Millisecond,
 		// Chat/Twitch
 		ChatHistoryHeader: "Сообщения из чата",
 		ChatMax:           30,
 		// По умолчанию используем Google TTS
-		TTSService: "gemini",
+		TTSService:          "gemini",
+		TTSFallbackCooldown: time.Minute,
 		YandexTTS: YandexTTSConfig{
 			APIKey:  "",
 			Voice:   "omazh",
@@ -178,6 +291,21 @@ func Defaults() *Config {
 			Emotion: "evil",
 			Volume:  100,
 		},
+		TTSCache: TTSCacheConfig{
+			Enabled:   false, // включается явно: фразы остаются на диске до TTL
+			Dir:       "cache\\tts",
+			MaxSizeMB: 200,
+			TTL:       30 * 24 * time.Hour,
+		},
+		TTSNormalize: TTSNormalizeConfig{
+			Enabled:    true,
+			Dictionary: "pronunciation.yaml",
+		},
+		TTSChunk: TTSChunkConfig{
+			Enabled:  true,
+			MaxChars: 300,
+			Parallel: 2,
+		},
 		StateServer: StateServerConfig{
 			Enabled:  false,
 			BindAddr: "127.0.0.1:3000",
@@ -205,8 +333,22 @@ func Defaults() *Config {
 			InputType:        "prompt",
 			Endpoint:         "https://texttospeech.googleapis.com/v1beta1/text:synthesize",
 		},
+		ControlAPI: ControlAPIConfig{
+			Enabled:        false,
+			BindAddr:       "127.0.0.1:3100",
+			Dashboard:      true,
+			DashboardTicks: 20,
+			Overlay:        true,
+			Metrics:        true,
+		},
+		Audit: AuditConfig{
+			Enabled:   false, // в журнал пишутся промпты, чат и речь стримера — только по явному согласию
+			Dir:       "logs\\audit",
+			MaxSizeMB: 50,
+		},
 		StateHeader: "Состояние игры",
 		StateMax:    3,
+		Sections:    defaultSections(),
 		VTube: VTubeConfig{
 			Enabled:         false,
 			WSURL:           "ws://localhost:8001",
@@ -214,52 +356,58 @@ func Defaults() *Config {
 			PluginDeveloper: "OpenAIClient",
 			APIVersion:      "1.0",
 			ResetEmotion:    "reset",
+			LipSync: LipSyncConfig{
+				FPS:        30,
+				Gain:       1,
+				OpenParam:  "MouthOpen",
+				SmileParam: "MouthSmile",
+			},
 		},
 	}
 }
 
-// NewConfig загружает конфигурацию приложения.
-func NewConfig() *Config {
-	_ = godotenv.Load()
-
-	cfg := Defaults()
-	_ = env.Parse(cfg)
-
-	// Обработка CHARACTER_LIST из .env (JSON-массив объектов с полями tags/text
-	cfg.LoadCharacterListFromEnv()
-
-	// Валидация для Google TTS: проверка наличия и доступности файла ключа
-	if strings.EqualFold(cfg.TTSService, "google") {
-		cred := strings.TrimSpace(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
-		if cred == "" {
-			panic(fmt.Errorf("google tts: переменная окружения GOOGLE_APPLICATION_CREDENTIALS не задана; укажите её или задайте путь к ключу сервисного аккаунта"))
-		}
-		if _, err := os.Stat(cred); err != nil {
-			panic(fmt.Errorf("google tts: файл ключа не найден: %s", cred))
-		}
-	}
+// NewConfig загружает конфигурацию приложения: дефолты, файл DefaultFile (если есть), затем .env и переменные окружения.
+// Ошибки разбора собираются в *ValidationError; конфиг возвращается и при ошибке (некорректные значения — дефолтные).
+// Проверка согласованности настроек для запуска — Validate.
+func NewConfig() (*Config, error) {
+	return NewConfigFrom(File{})
+}
 
-	return cfg
+// NewConfigFrom — как NewConfig, но с явным файлом конфига и профилем (флаги -config и -profile).
+func NewConfigFrom(f File) (*Config, error) {
+	// Окружение процесса до загрузки .env — нужно горячей перезагрузке (см. Reload)
+	processEnv = environMap()
+	source = f
+	_ = godotenv.Load()
+	return load(f, environMap())
 }
 
 // LoadCharacterListFromEnv парсит переменную окружения CHARACTER_LIST.
 // Поддерживаются два формата:
 // 1) Новый: JSON-массив объектов {"tags": [..], "text": "..."}
 // 2) Старый: строка с элементами, разделёнными ';' — каждый элемент становится Text, теги пустые
-func (c *Config) LoadCharacterListFromEnv() {
-	raw := strings.TrimSpace(os.Getenv("CHARACTER_LIST"))
+func (c *Config) LoadCharacterListFromEnv() error {
+	return c.loadCharacterList(os.Getenv("CHARACTER_LIST"))
+}
+
+// loadCharacterList разбирает значение CHARACTER_LIST; пустое значение оставляет дефолт.
+// Значение, начинающееся с '[', считается JSON: ошибка разбора возвращается, список не меняется.
+func (c *Config) loadCharacterList(raw string) error {
+	raw = strings.TrimSpace(raw)
 	if raw == "" {
-		return // оставить дефолт
+		return nil // оставить дефолт
 	}
 
-	// Попробуем как JSON
 	if strings.HasPrefix(raw, "[") {
 		var items []CharacterItem
-		if err := json.Unmarshal([]byte(raw), &items); err == nil && len(items) > 0 {
-			c.CharacterList = items
-			return
+		if err := json.Unmarshal([]byte(raw), &items); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		if len(items) == 0 {
+			return errors.New("empty JSON array")
 		}
-		// Если JSON не распарсился — падаем в старый режим ниже
+		c.CharacterList = items
+		return nil
 	}
 
 	// Старый режим: разделитель ';'
@@ -275,4 +423,34 @@ func (c *Config) LoadCharacterListFromEnv() {
 	if len(out) > 0 {
 		c.CharacterList = out
 	}
+	return nil
+}
+
+// NormalizeTTSProvider приводит имя провайдера TTS к каноническому: пусто — google, псевдонимы — к основному имени.
+func NormalizeTTSProvider(name string) string {
+	switch name = strings.ToLower(strings.TrimSpace(name)); name {
+	case "":
+		return "google"
+	case "google-gemini":
+		return "gemini"
+	case "yc", "speechkit":
+		return "yandex"
+	}
+	return name
+}
+
+// TTSChain возвращает порядок провайдеров TTS: TTS_PROVIDERS, а если он пуст — один TTS_SERVICE. Повторы отброшены.
+func (c *Config) TTSChain() []string {
+	raw := c.TTSProviders
+	if len(raw) == 0 {
+		raw = []string{c.TTSService}
+	}
+	out := make([]string, 0, len(raw))
+	for _, name := range raw {
+		name = NormalizeTTSProvider(name)
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
 }
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile — файл конфига, который читается, если путь не задан явно (флаг -config).
+const DefaultFile = "companion.yaml"
+
+// File — структурированный файл конфига (YAML) и выбранный в нём профиль.
+// Значения файла ложатся поверх дефолтов; .env и переменные окружения перекрывают файл.
+type File struct {
+	Path    string // Путь к файлу; пусто — DefaultFile, если он существует
+	Profile string // Имя профиля из секции profiles; пусто — только общие настройки
+}
+
+// source — файл, с которым загружен конфиг; используется при перезагрузке (см. Reload)
+var source File
+
+// FilePath возвращает путь к файлу конфига, из которого загружены настройки, или пустую строку.
+func FilePath() string {
+	if source.Path != "" {
+		return source.Path
+	}
+	if _, err := os.Stat(DefaultFile); err == nil {
+		return DefaultFile
+	}
+	return ""
+}
+
+// apply читает файл и накладывает общие настройки, затем профиль. При ошибке cfg не меняется.
+func (f File) apply(cfg *Config) error {
+	path := f.Path
+	if path == "" {
+		path = DefaultFile
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if f.Profile != "" {
+				return fmt.Errorf("profile %q: config file %s not found", f.Profile, path)
+			}
+			return nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+
+	// Блоки других пакетов (RegisterSection) разбираются в копии: при ошибке cfg остаётся прежним
+	next := *cfg
+	next.Sections = cloneSections(cfg.Sections)
+	doc := document(&next, reflect.StructField{Name: "Profiles", Type: reflect.TypeOf(map[string]yaml.Node{}), Tag: `yaml:"profiles"`})
+	if err := decodeStrict(data, doc.Addr().Interface()); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	next = doc.Field(0).Interface().(Config)
+
+	if f.Profile != "" {
+		profiles := doc.FieldByName("Profiles").Interface().(map[string]yaml.Node)
+		node, ok := profiles[f.Profile]
+		if !ok {
+			names := make([]string, 0, len(profiles))
+			for name := range profiles {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return fmt.Errorf("%s: profile %q not found (available: %s)", path, f.Profile, strings.Join(names, ", "))
+		}
+		raw, err := yaml.Marshal(&node)
+		if err != nil {
+			return fmt.Errorf("%s: profile %q: %w", path, f.Profile, err)
+		}
+		doc := document(&next)
+		if err := decodeStrict(raw, doc.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s: profile %q: %w", path, f.Profile, err)
+		}
+		next = doc.Field(0).Interface().(Config)
+	}
+
+	*cfg = next
+	return nil
+}
+
+// decodeStrict разбирает YAML в out; неизвестные ключи — ошибка (опечатки не проходят молча).
+func decodeStrict(data []byte, out any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(out)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+	var te *yaml.TypeError
+	if !errors.As(err, &te) {
+		return err
+	}
+	// "line 3: field foo not found in type struct {...}" → "line 3: unknown field foo"
+	problems := make([]string, 0, len(te.Errors))
+	for _, e := range te.Errors {
+		if i := strings.Index(e, " not found in type "); i >= 0 {
+			e = strings.Replace(e[:i], "field ", "unknown field ", 1)
+		}
+		problems = append(problems, e)
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
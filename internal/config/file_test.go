@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFile = `
+assistant_sentences: 2
+timer_interval_seconds: 30
+speech_prompt: ["доложи статус", "что видно?"]
+vtube:
+  enabled: true
+  ws_url: ws://localhost:9000
+test_section:
+  voice: filipp
+profiles:
+  dota:
+    timer_interval_seconds: 10
+    vtube:
+      ws_url: ws://localhost:9001
+    test_section:
+      period: 5s
+  quiet:
+    assistant_sentences: 1
+`
+
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "companion.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFile_ProfileOverlaysBase(t *testing.T) {
+	path := writeFile(t, testFile)
+	cfg, err := load(File{Path: path, Profile: "dota"}, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.TimerIntervalSeconds != 10 {
+		t.Errorf("timer = %d, want 10 from profile", cfg.TimerIntervalSeconds)
+	}
+	if cfg.AssistantSentences != 2 || len(cfg.SpeechPrompt) != 2 {
+		t.Errorf("base settings lost: sentences=%d prompts=%q", cfg.AssistantSentences, cfg.SpeechPrompt)
+	}
+	// Вложенный блок профиля меняет только указанные поля
+	if !cfg.VTube.Enabled || cfg.VTube.WSURL != "ws://localhost:9001" || cfg.VTube.APIVersion != Defaults().VTube.APIVersion {
+		t.Errorf("vtube = %+v, want enabled from base, ws_url from profile, api_version default", cfg.VTube)
+	}
+	if s := cfg.Section("test_section").(*testSection); s.Voice != "filipp" || s.Period != 5*time.Second {
+		t.Errorf("section = %+v, want voice from base and period from profile", s)
+	}
+
+	base, err := load(File{Path: path}, nil)
+	if err != nil {
+		t.Fatalf("load without profile: %v", err)
+	}
+	if base.TimerIntervalSeconds != 30 || base.VTube.WSURL != "ws://localhost:9000" {
+		t.Errorf("without profile: timer=%d ws_url=%s, want base values", base.TimerIntervalSeconds, base.VTube.WSURL)
+	}
+}
+
+func TestFile_EnvOverridesFile(t *testing.T) {
+	path := writeFile(t, testFile)
+	cfg, err := load(File{Path: path, Profile: "dota"}, map[string]string{
+		"TIMER_INTERVAL_SECONDS": "7",
+		"VTUBE_WS_URL":           "ws://remote:8001",
+		"TEST_SECTION_VOICE":     "alena",
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.TimerIntervalSeconds != 7 || cfg.VTube.WSURL != "ws://remote:8001" {
+		t.Errorf("timer=%d ws_url=%s, want values from env", cfg.TimerIntervalSeconds, cfg.VTube.WSURL)
+	}
+	if s := cfg.Section("test_section").(*testSection); s.Voice != "alena" || s.Period != 5*time.Second {
+		t.Errorf("section = %+v, want voice from env and period from profile", s)
+	}
+	if cfg.AssistantSentences != 2 {
+		t.Errorf("sentences = %d, want 2 from file (not set in env)", cfg.AssistantSentences)
+	}
+}
+
+func TestFile_UnknownProfile(t *testing.T) {
+	path := writeFile(t, testFile)
+	err := File{Path: path, Profile: "cs"}.apply(Defaults())
+	want := path + `: profile "cs" not found (available: dota, quiet)`
+	if err == nil || err.Error() != want {
+		t.Fatalf("err = %v, want %q", err, want)
+	}
+
+	t.Chdir(t.TempDir()) // без companion.yaml профиль выбрать не из чего
+	if err := (File{Profile: "dota"}).apply(Defaults()); err == nil || !strings.Contains(err.Error(), `profile "dota": config file companion.yaml not found`) {
+		t.Fatalf("missing default file: err = %v", err)
+	}
+}
+
+func TestFile_UnknownKeysRejected(t *testing.T) {
+	tests := []struct {
+		name, data, profile, want string
+	}{
+		{"top level", "assistant_sentences: 2\ntimer_interval: 5\n", "", "line 2: unknown field timer_interval"},
+		{"nested block", "vtube:\n  enabled: true\n  ws: ws://x\n", "", "line 3: unknown field ws"},
+		{"section block", "test_section:\n  voise: x\n", "", "line 2: unknown field voise"},
+		{"inside profile", "profiles:\n  dota:\n    timer: 1\n", "dota", "unknown field timer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.data)
+			cfg := Defaults()
+			err := File{Path: path, Profile: tt.profile}.apply(cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+			if tt.profile != "" && !strings.Contains(err.Error(), `profile "`+tt.profile+`"`) {
+				t.Fatalf("err = %v, want profile name in message", err)
+			}
+			if cfg.AssistantSentences != Defaults().AssistantSentences || cfg.VTube.Enabled {
+				t.Fatalf("config changed despite error")
+			}
+		})
+	}
+}
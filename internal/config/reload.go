@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/joho/godotenv"
+)
+
+// processEnv — окружение процесса до загрузки .env: переменные ОС приоритетнее файла и при перезагрузке.
+var processEnv map[string]string
+
+// hotReloadable — настройки, которые применяются на лету (промпты, персонажи, темп тиков).
+// Остальные требуют перезапуска: их меняют подключения, клиенты и серверы, созданные на старте.
+var hotReloadable = map[string]bool{
+	"ASSISTANT_PROMPT":       true,
+	"ASSISTANT_SENTENCES":    true,
+	"CHARACTER_LIST":         true,
+	"SPEECH_PROMPT":          true,
+	"HISTORY_HEADER":         true,
+	"SPEECH_HEADER":          true,
+	"SPEECH_DEFAULT_ENABLED": true,
+	"CHAT_HISTORY_HEADER":    true,
+	"STATE_HEADER":           true,
+	"IMAGES_TO_PICK":         true,
+	"TIMER_INTERVAL_SECONDS": true,
+	"TICK_TIMEOUT_SECONDS":   true,
+	"OVERLAP_POLICY":         true,
+	"MAX_CONSECUTIVE_ERRORS": true,
+	"ENABLE_EARLY_TICK":      true,
+	"BARGE_IN":               true,
+	"BARGE_IN_FADE":          true,
+	"TTS_MARKUP":             true,
+}
+
+// Reload заново читает файл конфига (тот же профиль, что при запуске) и .env (path; пусто — ".env") поверх дефолтов.
+// Переменные ОС по-прежнему приоритетнее .env, а .env — файла конфига.
+// В отличие от NewConfig не трогает окружение процесса; конфиг с ошибками разбора или проверки не возвращается.
+// validate — та же проверка, что при запуске (с настройками провайдеров и словарём); nil — только Validate.
+func Reload(path string, validate func(*Config) error) (*Config, error) {
+	if validate == nil {
+		validate = (*Config).Validate
+	}
+	if path == "" {
+		path = ".env"
+	}
+	file, err := godotenv.Read(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	merged := make(map[string]string, len(file)+len(processEnv))
+	for k, v := range file {
+		merged[k] = v
+	}
+	for k, v := range processEnv {
+		merged[k] = v
+	}
+
+	cfg, err := load(source, merged)
+	ve := &ValidationError{}
+	ve.merge(err)
+	ve.merge(validate(cfg))
+	if err := ve.err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseEnv применяет значения окружения к cfg, включая блоки других пакетов.
+func parseEnv(cfg *Config, environment map[string]string) error {
+	if err := env.Parse(cfg, env.Options{Environment: environment}); err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(cfg.Sections)) {
+		if err := env.Parse(cfg.Sections[key], env.Options{Environment: environment}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff сравнивает конфиги по именам переменных: hot — изменённые настройки, применяемые на лету,
+// restart — изменённые настройки, которые вступят в силу только после перезапуска.
+func Diff(old, next *Config) (hot, restart []string) {
+	walk(reflect.ValueOf(old).Elem(), reflect.ValueOf(next).Elem(), func(name string, a, b reflect.Value) {
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return
+		}
+		if hotReloadable[name] {
+			hot = append(hot, name)
+		} else {
+			restart = append(restart, name)
+		}
+	})
+	return hot, restart
+}
+
+// ApplyHot возвращает копию cur, в которой горячие настройки взяты из next; остальное остаётся как было.
+func ApplyHot(cur, next *Config) *Config {
+	out := *cur
+	walk(reflect.ValueOf(&out).Elem(), reflect.ValueOf(next).Elem(), func(name string, dst, src reflect.Value) {
+		if hotReloadable[name] {
+			dst.Set(src)
+		}
+	})
+	return &out
+}
+
+// walk обходит поля конфига (включая вложенные структуры и блоки других пакетов)
+// и вызывает fn для каждой настройки с её именем переменной.
+func walk(a, b reflect.Value, fn func(name string, a, b reflect.Value)) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("env"), ",")
+		switch {
+		case f.Name == "CharacterList":
+			fn("CHARACTER_LIST", a.Field(i), b.Field(i))
+		case f.Name == "Sections":
+			walkSections(a.Field(i), b.Field(i), fn)
+		case name != "":
+			fn(name, a.Field(i), b.Field(i))
+		case f.Type.Kind() == reflect.Struct:
+			walk(a.Field(i), b.Field(i), fn)
+		}
+	}
+}
+
+// walkSections обходит блоки, объявленные в обоих конфигах, по алфавиту ключей.
+func walkSections(a, b reflect.Value, fn func(name string, a, b reflect.Value)) {
+	keys := make([]string, 0, a.Len())
+	for _, k := range a.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		sa, sb := a.MapIndex(reflect.ValueOf(key)), b.MapIndex(reflect.ValueOf(key))
+		if !sb.IsValid() {
+			continue
+		}
+		walk(sa.Elem().Elem(), sb.Elem().Elem(), fn)
+	}
+}
+
+// environMap превращает os.Environ() в карту.
+func environMap() map[string]string {
+	out := map[string]string{}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			out[k] = v
+		}
+	}
+	return out
+}
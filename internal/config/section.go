@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// section — блок настроек, который объявил другой пакет (TTS-провайдер со своим типом настроек).
+// Конфиг не знает тип блока: разбирает его по env- и yaml-тегам так же, как собственные поля.
+type section struct {
+	defaults func() any // новый указатель на структуру со значениями по умолчанию
+	secrets  []string   // переменные блока, маскируемые в Effective
+}
+
+var (
+	registeredMu sync.RWMutex
+	registered   = map[string]section{}
+)
+
+// RegisterSection объявляет блок настроек под ключом key (ключ в YAML, например openai_tts).
+// defaults возвращает указатель на новую структуру с дефолтами; поля описываются тегами env и yaml.
+// secrets — имена переменных блока, значения которых не выводятся в Effective. Вызывается из init().
+func RegisterSection(key string, defaults func() any, secrets ...string) {
+	if v := reflect.ValueOf(defaults()); v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic("config: section " + key + ": defaults must return a pointer to struct")
+	}
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	if _, dup := registered[key]; dup {
+		panic("config: section registered twice: " + key)
+	}
+	registered[key] = section{defaults: defaults, secrets: secrets}
+}
+
+// Section возвращает блок настроек key (указатель на структуру, объявленную в RegisterSection) или nil.
+func (c *Config) Section(key string) any {
+	return c.Sections[key]
+}
+
+// defaultSections создаёт все объявленные блоки с дефолтами.
+func defaultSections() map[string]any {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	out := make(map[string]any, len(registered))
+	for key, s := range registered {
+		out[key] = s.defaults()
+	}
+	return out
+}
+
+// cloneSections копирует блоки, чтобы разбор файла с ошибкой не менял исходный конфиг.
+func cloneSections(in map[string]any) map[string]any {
+	out := make(map[string]any, len(in))
+	for key, v := range in {
+		p := reflect.New(reflect.TypeOf(v).Elem())
+		p.Elem().Set(reflect.ValueOf(v).Elem())
+		out[key] = p.Interface()
+	}
+	return out
+}
+
+// isSecret сообщает, маскируется ли переменная name в Effective.
+func isSecret(name string) bool {
+	if secrets[name] {
+		return true
+	}
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	for _, s := range registered {
+		if slices.Contains(s.secrets, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// document создаёт YAML-документ: поля cfg, объявленные блоки под своими ключами и поля extra (profiles).
+// Блоки — те же указатели, что в cfg.Sections: разбор документа пишет прямо в них.
+// Ключи без поля остаются неизвестными для decodeStrict, как и опечатки в общих настройках.
+func document(cfg *Config, extra ...reflect.StructField) reflect.Value {
+	fields := []reflect.StructField{{Name: "Config", Type: reflect.TypeOf(Config{}), Tag: `yaml:",inline"`}}
+	keys := slices.Sorted(maps.Keys(cfg.Sections))
+	for i, key := range keys {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Section%d", i),
+			Type: reflect.TypeOf(cfg.Sections[key]),
+			Tag:  reflect.StructTag(`yaml:"` + key + `"`),
+		})
+	}
+	doc := reflect.New(reflect.StructOf(append(fields, extra...))).Elem()
+	doc.Field(0).Set(reflect.ValueOf(*cfg))
+	for i, key := range keys {
+		doc.Field(i + 1).Set(reflect.ValueOf(cfg.Sections[key]))
+	}
+	return doc
+}
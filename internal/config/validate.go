@@ -0,0 +1,296 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError — все найденные проблемы конфига, по одной на строку.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+func (e *ValidationError) add(format string, args ...any) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// err возвращает nil, если проблем нет.
+func (e *ValidationError) err() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// merge добавляет проблемы из err (ValidationError или обычной ошибки).
+func (e *ValidationError) merge(err error) {
+	if err == nil {
+		return
+	}
+	if ve, ok := err.(*ValidationError); ok {
+		e.Problems = append(e.Problems, ve.Problems...)
+		return
+	}
+	e.Problems = append(e.Problems, err.Error())
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// load собирает конфиг из дефолтов, файла f и окружения environment. Некорректные значения не применяются
+// (остаётся прежнее) и попадают в ValidationError — все сразу, а не только первое.
+func load(f File, environment map[string]string) (*Config, error) {
+	ve := &ValidationError{}
+	clean := make(map[string]string, len(environment))
+	for k, v := range environment {
+		clean[k] = v
+	}
+
+	cfg := Defaults()
+	if err := f.apply(cfg); err != nil {
+		ve.add("config file: %v", err)
+	}
+	walk(reflect.ValueOf(cfg).Elem(), reflect.ValueOf(cfg).Elem(), func(name string, f, _ reflect.Value) {
+		raw, ok := clean[name]
+		if !ok || name == "CHARACTER_LIST" {
+			return
+		}
+		if msg := checkValue(f.Type(), raw); msg != "" {
+			ve.add("%s: %s: %q", name, msg, raw)
+			delete(clean, name)
+		}
+	})
+	if err := parseEnv(cfg, clean); err != nil {
+		ve.add("%v", err)
+	}
+	if err := cfg.loadCharacterList(environment["CHARACTER_LIST"]); err != nil {
+		ve.add("CHARACTER_LIST: %v", err)
+	}
+	return cfg, ve.err()
+}
+
+// checkValue проверяет, что строку можно разобрать в тип поля; возвращает описание ошибки.
+func checkValue(t reflect.Type, raw string) string {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case t == durationType:
+		if _, err := time.ParseDuration(raw); err != nil {
+			return "invalid duration (examples: 300ms, 5s, 1m)"
+		}
+	case t.Kind() == reflect.Bool:
+		if _, err := strconv.ParseBool(raw); err != nil {
+			return "invalid bool (true|false)"
+		}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return "invalid integer"
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return "invalid number"
+		}
+	}
+	return ""
+}
+
+// Validate проверяет согласованность настроек для запуска компаньона и возвращает все проблемы сразу.
+func (c *Config) Validate() error {
+	ve := &ValidationError{}
+
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		ve.add("OPENAI_API_KEY: not set")
+	}
+	if len(c.CharacterList) == 0 {
+		ve.add("CHARACTER_LIST: empty")
+	}
+
+	// Темп тиков
+	if c.TimerIntervalSeconds <= 0 {
+		ve.add("TIMER_INTERVAL_SECONDS: must be > 0, got %d", c.TimerIntervalSeconds)
+	}
+	if c.TickTimeoutSeconds <= 0 {
+		ve.add("TICK_TIMEOUT_SECONDS: must be > 0, got %d", c.TickTimeoutSeconds)
+	}
+	switch c.OverlapPolicy {
+	case "skip", "preempt":
+	default:
+		ve.add("OVERLAP_POLICY: unknown value %q (skip|preempt)", c.OverlapPolicy)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.BargeIn)) {
+	case "off", "stop", "fade":
+	default:
+		ve.add("BARGE_IN: unknown value %q (off|stop|fade)", c.BargeIn)
+	}
+	for i, item := range c.CharacterList {
+		switch strings.ToLower(strings.TrimSpace(item.BargeIn)) {
+		case "", "off", "stop", "fade":
+		default:
+			ve.add("CHARACTER_LIST[%d].barge_in: unknown value %q (off|stop|fade)", i, item.BargeIn)
+		}
+	}
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"BARGE_IN_FADE", c.BargeInFade},
+		{"STT_HANDY_WINDOW", c.STTHandyWindow},
+		{"STT_HOTKEY_DELAY", c.STTHotkeyDelay},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"SHUTDOWN_DRAIN", c.ShutdownDrain},
+		{"TTS_CACHE_TTL", c.TTSCache.TTL},
+		{"TTS_FALLBACK_COOLDOWN", c.TTSFallbackCooldown},
+	} {
+		if d.value < 0 {
+			ve.add("%s: must not be negative, got %s", d.name, d.value)
+		}
+	}
+
+	// Аудиовыходы: несколько одновременно, discard — только один
+	sinks := map[string]bool{}
+	for _, s := range c.AudioSinks {
+		switch name := strings.ToLower(strings.TrimSpace(s)); name {
+		case "speaker", "files", "session", "discard":
+			sinks[name] = true
+		default:
+			ve.add("AUDIO_SINKS: unknown sink %q (speaker|files|session|discard)", s)
+		}
+	}
+	if sinks["discard"] && len(sinks) > 1 {
+		ve.add("AUDIO_SINKS: discard cannot be combined with other sinks")
+	}
+	if (sinks["files"] || sinks["session"]) && strings.TrimSpace(c.AudioRecordDir) == "" {
+		ve.add("AUDIO_RECORD_DIR: required for AUDIO_SINKS files and session")
+	}
+
+	if l := c.AudioLoudness; l.Enabled {
+		if l.TargetLUFS < -40 || l.TargetLUFS > -5 {
+			ve.add("AUDIO_LOUDNESS_TARGET: must be within -40..-5 LUFS, got %g", l.TargetLUFS)
+		}
+		if l.MaxGainDB < 0 {
+			ve.add("AUDIO_LOUDNESS_MAX_GAIN_DB: must not be negative, got %g", l.MaxGainDB)
+		}
+		if l.CeilingDB < -20 || l.CeilingDB > 0 {
+			ve.add("AUDIO_LIMITER_CEILING_DB: must be within -20..0 dBFS, got %g", l.CeilingDB)
+		}
+	}
+
+	// Имена и настройки TTS-провайдеров цепочки проверяет реестр провайдеров (tts.Validate)
+
+	if c.TTSChunk.Enabled {
+		if c.TTSChunk.MaxChars < 50 {
+			ve.add("TTS_CHUNK_MAX_CHARS: must be >= 50, got %d", c.TTSChunk.MaxChars)
+		}
+		if c.TTSChunk.Parallel < 1 {
+			ve.add("TTS_CHUNK_PARALLEL: must be >= 1, got %d", c.TTSChunk.Parallel)
+		}
+	}
+	if c.TTSCache.Enabled && c.TTSCache.MaxSizeMB <= 0 {
+		ve.add("TTS_CACHE_MAX_SIZE_MB: must be > 0, got %d", c.TTSCache.MaxSizeMB)
+	}
+
+	// Звуки уведомлений (пустой путь — встроенный дефолт notify)
+	checkFile(ve, "NOTIFICATION_SEND_AI", c.NotificationSendAI, "")
+	checkFile(ve, "NOTIFICATION_SEND_TTS", c.NotificationSendTTS, "")
+
+	// VTube Studio
+	if c.VTube.Enabled {
+		if u, err := url.Parse(c.VTube.WSURL); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			ve.add("VTUBE_WS_URL: invalid WebSocket URL %q (example: ws://localhost:8001)", c.VTube.WSURL)
+		}
+		if strings.TrimSpace(c.VTubeAPIKey) == "" {
+			ve.add("VTUBE_API_KEY: required when VTUBE_ENABLED=true")
+		}
+		if ls := c.VTube.LipSync; ls.Enabled {
+			if ls.FPS < 10 || ls.FPS > 60 {
+				ve.add("VTUBE_LIPSYNC_FPS: must be within 10..60, got %d", ls.FPS)
+			}
+			if ls.Gain <= 0 {
+				ve.add("VTUBE_LIPSYNC_GAIN: must be > 0, got %g", ls.Gain)
+			}
+			if strings.TrimSpace(ls.OpenParam) == "" {
+				ve.add("VTUBE_LIPSYNC_OPEN_PARAM: required when VTUBE_LIPSYNC=true")
+			}
+		}
+	}
+	return ve.err()
+}
+
+// checkFile проверяет, что файл существует и читается; пустой путь — проблема, только если задан reason.
+func checkFile(ve *ValidationError, name, path, reason string) {
+	if msg := FileProblem(name, path, reason); msg != "" {
+		ve.add("%s", msg)
+	}
+}
+
+// FileProblem проверяет, что файл настройки name читается; пустой путь — проблема, только если задан reason.
+// Возвращает описание проблемы или пустую строку.
+func FileProblem(name, path, reason string) string {
+	if strings.TrimSpace(path) == "" {
+		if reason != "" {
+			return name + ": " + reason
+		}
+		return ""
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Sprintf("%s: cannot read %q: %v", name, path, err)
+	}
+	_ = f.Close()
+	return ""
+}
+
+// secrets — переменные, значения которых не выводятся в Effective; секреты блоков других пакетов — в RegisterSection.
+var secrets = map[string]bool{
+	"OPENAI_API_KEY":          true,
+	"YC_TTS_API_KEY":          true,
+	"TWITCH_OAUTH_TOKEN":      true,
+	"VTUBE_API_KEY":           true,
+	"STATE_SERVER_AUTH_TOKEN": true,
+	"CONTROL_API_AUTH_TOKEN":  true,
+}
+
+// Effective возвращает итоговые значения настроек строками NAME=value; секреты замаскированы.
+func (c *Config) Effective() []string {
+	v := reflect.ValueOf(c).Elem()
+	out := []string{"OPENAI_API_KEY=" + mask(os.Getenv("OPENAI_API_KEY"))}
+	walk(v, v, func(name string, f, _ reflect.Value) {
+		var s string
+		switch {
+		case isSecret(name):
+			s = mask(f.String())
+		case name == "CHARACTER_LIST":
+			names := make([]string, 0, f.Len())
+			for i := 0; i < f.Len(); i++ {
+				names = append(names, characterName(f.Index(i).Interface().(CharacterItem), i))
+			}
+			s = fmt.Sprintf("%d items [%s]", f.Len(), strings.Join(names, ", "))
+		default:
+			s = fmt.Sprint(f.Interface())
+		}
+		out = append(out, name+"="+s)
+	})
+	return out
+}
+
+func mask(s string) string {
+	if s == "" {
+		return "(unset)"
+	}
+	return "***"
+}
+
+// characterName — имя персонажа для вывода; безымянные нумеруются.
+func characterName(it CharacterItem, i int) string {
+	if it.Name != "" {
+		return it.Name
+	}
+	return "#" + strconv.Itoa(i+1)
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSection — блок настроек другого пакета (как у TTS-провайдеров) для проверки RegisterSection.
+type testSection struct {
+	Voice  string        `env:"TEST_SECTION_VOICE" yaml:"voice"`
+	Token  string        `env:"TEST_SECTION_TOKEN" yaml:"token"`
+	Period time.Duration `env:"TEST_SECTION_PERIOD" yaml:"period"`
+}
+
+func init() {
+	RegisterSection("test_section", func() any { return &testSection{Voice: "default", Period: time.Second} }, "TEST_SECTION_TOKEN")
+}
+
+// problems возвращает проблемы из ValidationError; другая ошибка — провал теста.
+func problems(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("error %v is not *ValidationError", err)
+	}
+	return ve.Problems
+}
+
+// hasProblem сообщает, есть ли проблема, начинающаяся с prefix.
+func hasProblem(list []string, prefix string) bool {
+	return slices.ContainsFunc(list, func(p string) bool { return strings.HasPrefix(p, prefix) })
+}
+
+func TestLoad_ReportsAllProblemsAndKeepsDefaults(t *testing.T) {
+	t.Chdir(t.TempDir()) // без companion.yaml
+	cfg, err := load(File{}, map[string]string{
+		"TIMER_INTERVAL_SECONDS": "five",
+		"BARGE_IN_FADE":          "300",
+		"DEBUG_MODE":             "maybe",
+		"AUDIO_DUCK_DB":          "-x",
+		"TEST_SECTION_PERIOD":    "soon",
+		"CHARACTER_LIST":         `[{"text": `,
+		"ASSISTANT_SENTENCES":    "5", // корректные значения применяются рядом с ошибками
+		"TEST_SECTION_VOICE":     "alena",
+	})
+	got := problems(t, err)
+	for _, name := range []string{"TIMER_INTERVAL_SECONDS", "BARGE_IN_FADE", "DEBUG_MODE", "AUDIO_DUCK_DB", "TEST_SECTION_PERIOD", "CHARACTER_LIST"} {
+		if !hasProblem(got, name+":") {
+			t.Errorf("no problem reported for %s; got %q", name, got)
+		}
+	}
+	if len(got) != 6 {
+		t.Errorf("got %d problems, want 6: %q", len(got), got)
+	}
+
+	def := Defaults()
+	if cfg.TimerIntervalSeconds != def.TimerIntervalSeconds || cfg.BargeInFade != def.BargeInFade ||
+		cfg.DebugMode != def.DebugMode || cfg.AudioDuckDB != def.AudioDuckDB {
+		t.Errorf("bad values must keep defaults: timer=%d fade=%s debug=%v duck=%g",
+			cfg.TimerIntervalSeconds, cfg.BargeInFade, cfg.DebugMode, cfg.AudioDuckDB)
+	}
+	if len(cfg.CharacterList) != len(def.CharacterList) {
+		t.Errorf("broken CHARACTER_LIST replaced the default list: %+v", cfg.CharacterList)
+	}
+	if cfg.AssistantSentences != 5 {
+		t.Errorf("ASSISTANT_SENTENCES = %d, want 5", cfg.AssistantSentences)
+	}
+	s := cfg.Section("test_section").(*testSection)
+	if s.Period != time.Second || s.Voice != "alena" {
+		t.Errorf("section = %+v, want default period and voice from env", s)
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "")
+	cfg, err := load(File{}, map[string]string{
+		"TIMER_INTERVAL_SECONDS": "0",
+		"OVERLAP_POLICY":         "queue",
+		"BARGE_IN":               "loud",
+		"AUDIO_SINKS":            "discard,speaker",
+		"VTUBE_ENABLED":          "true",
+		"VTUBE_WS_URL":           "http://localhost:8001",
+		"NOTIFICATION_SEND_AI":   "missing.mp3",
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	got := problems(t, cfg.Validate())
+	for _, prefix := range []string{
+		"OPENAI_API_KEY:", "TIMER_INTERVAL_SECONDS:", "OVERLAP_POLICY:", "BARGE_IN:", "AUDIO_SINKS:",
+		"VTUBE_WS_URL:", "VTUBE_API_KEY:", "NOTIFICATION_SEND_AI:",
+	} {
+		if !hasProblem(got, prefix) {
+			t.Errorf("no problem %s in %q", prefix, got)
+		}
+	}
+	if msg := cfg.Validate().Error(); !strings.HasPrefix(msg, "invalid config:\n  - ") || strings.Count(msg, "\n  - ") != len(got) {
+		t.Errorf("Error() must list every problem on its own line:\n%s", msg)
+	}
+}
+
+func TestValidate_DefaultsWithKeyAreValid(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	cfg := Defaults()
+	cfg.NotificationSendAI, cfg.NotificationSendTTS = "", "" // звуки лежат рядом с бинарником, не в папке теста
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+}
+
+func TestEffective_MasksSecrets(t *testing.T) {
+	t.Chdir(t.TempDir())
+	const secret = "s3cret-value"
+	t.Setenv("OPENAI_API_KEY", secret)
+	environment := map[string]string{"TEST_SECTION_TOKEN": secret}
+	for name := range secrets {
+		environment[name] = secret
+	}
+	cfg, err := load(File{}, environment)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	lines := cfg.Effective()
+	for _, line := range lines {
+		if strings.Contains(line, secret) {
+			t.Errorf("secret leaked: %s", line)
+		}
+	}
+	for name := range environment {
+		if !slices.Contains(lines, name+"=***") {
+			t.Errorf("%s not masked", name)
+		}
+	}
+	if !slices.Contains(lines, "OPENAI_API_KEY=***") {
+		t.Errorf("OPENAI_API_KEY not masked")
+	}
+	if !slices.Contains(lines, "TEST_SECTION_VOICE=default") {
+		t.Errorf("section settings missing from Effective: %q", lines)
+	}
+}
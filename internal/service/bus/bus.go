@@ -0,0 +1,240 @@
+package bus
+
+import (
+	"OpenAIClient/internal/config"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SchemaVersion — версия JSON-схемы событий; меняется только при несовместимых изменениях.
+const SchemaVersion = 1
+
+// Типы событий
+const (
+	TypeTickStart        = "tick.start"
+	TypeTickEnd          = "tick.end"
+	TypePromptSent       = "prompt.sent"
+	TypeResponseReceived = "response.received"
+	TypeTTSStart         = "tts.start"
+	TypeTTSEnd           = "tts.end"
+	TypeVTubeTrigger     = "vtube.trigger"
+	TypeSubtitle         = "subtitle"
+	TypeConfigReload     = "config.reload"
+	TypeError            = "error"
+)
+
+// Event — внутреннее событие компаньона в стабильной JSON-схеме.
+type Event struct {
+	V      int       `json:"v"`
+	Seq    uint64    `json:"seq"`
+	Type   string    `json:"type"`
+	At     time.Time `json:"at"`
+	TickID int64     `json:"tick_id,omitempty"`
+	Data   any       `json:"data,omitempty"`
+}
+
+// TickStart — данные tick.start.
+type TickStart struct {
+	Trigger   string                `json:"trigger"` // timer|early|forced|say|barge-in
+	Persona   string                `json:"persona"`
+	Character *config.CharacterItem `json:"character,omitempty"` // выбранный элемент CHARACTER_LIST
+}
+
+// TickEnd — данные tick.end.
+type TickEnd struct {
+	Status     string `json:"status"` // ok|empty|interrupted|error
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// PromptSent — данные prompt.sent.
+type PromptSent struct {
+	Speech          int      `json:"speech"`
+	Chat            int      `json:"chat"`
+	State           int      `json:"state"`
+	Images          int      `json:"images"`
+	ImagePaths      []string `json:"image_paths,omitempty"`
+	SystemPrompt    string   `json:"system_prompt"`
+	AssistantPrompt string   `json:"assistant_prompt"`
+	UserPrompt      string   `json:"user_prompt"`
+}
+
+// ResponseReceived — данные response.received.
+type ResponseReceived struct {
+	Text      string `json:"text"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// TTSStart — данные tts.start.
+type TTSStart struct {
+	Provider string `json:"provider"`
+	Text     string `json:"text"`
+}
+
+// TTSEnd — данные tts.end: время синтеза и фактического воспроизведения.
+type TTSEnd struct {
+	Provider    string `json:"provider"` // провайдер, фактически синтезировавший речь (с учётом fallback)
+	Format      string `json:"format"`
+	SynthMs     int64  `json:"synth_ms"`
+	PlaybackMs  int64  `json:"playback_ms"`
+	Interrupted bool   `json:"interrupted,omitempty"`
+}
+
+// VTubeTrigger — данные vtube.trigger.
+type VTubeTrigger struct {
+	Tags  []string `json:"tags"`
+	Reset bool     `json:"reset,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// Subtitle — данные subtitle: реплика начала звучать (для оверлея субтитров).
+type Subtitle struct {
+	Text       string   `json:"text"`
+	Persona    string   `json:"persona"`
+	Emotion    []string `json:"emotion,omitempty"` // теги эмоций персонажа (VTube)
+	DurationMs int64    `json:"duration_ms"`       // длительность аудио; 0 — неизвестна
+}
+
+// ConfigReload — данные config.reload: итог горячей перезагрузки конфига.
+type ConfigReload struct {
+	Applied         []string `json:"applied,omitempty"`
+	RestartRequired []string `json:"restart_required,omitempty"`
+	Error           string   `json:"error,omitempty"` // конфиг не принят, действует прежний
+}
+
+// Error — данные error.
+type Error struct {
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+// Bus — неблокирующая рассылка событий подписчикам. Медленный подписчик теряет события, а не тормозит тик;
+// подписчик без потерь (SubscribeLossless) копит их в очереди.
+// Nil *Bus допустим: Publish ничего не делает.
+type Bus struct {
+	seq    atomic.Uint64
+	mu     sync.RWMutex
+	subs   map[chan Event]struct{}
+	queues map[*queue]struct{}
+}
+
+func New() *Bus {
+	return &Bus{subs: map[chan Event]struct{}{}, queues: map[*queue]struct{}{}}
+}
+
+// Publish рассылает событие всем подписчикам.
+func (b *Bus) Publish(typ string, tickID int64, data any) {
+	if b == nil {
+		return
+	}
+	ev := Event{V: SchemaVersion, Seq: b.seq.Add(1), Type: typ, At: time.Now(), TickID: tickID, Data: data}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default: // подписчик не успевает — дроп
+		}
+	}
+	for q := range b.queues {
+		q.push(ev)
+	}
+}
+
+// Subscribe возвращает канал событий и функцию отписки. buf — размер буфера подписчика.
+func (b *Bus) Subscribe(buf int) (<-chan Event, func()) {
+	ch := make(chan Event, max(1, buf))
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// SubscribeLossless — подписка без потерь: события копятся в очереди без предела, пока подписчик занят,
+// и Publish при этом не ждёт. Для потребителей, которым нужна полная история (журнал тиков).
+func (b *Bus) SubscribeLossless() (<-chan Event, func()) {
+	q := &queue{wake: make(chan struct{}, 1), done: make(chan struct{})}
+	out := make(chan Event)
+	b.mu.Lock()
+	b.queues[q] = struct{}{}
+	b.mu.Unlock()
+	go q.pump(out)
+
+	var once sync.Once
+	return out, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.queues, q)
+			b.mu.Unlock()
+			close(q.done)
+		})
+	}
+}
+
+// queue — очередь подписчика без потерь.
+type queue struct {
+	mu     sync.Mutex
+	events []Event
+	wake   chan struct{} // в очереди появились события
+	done   chan struct{} // отписка
+}
+
+func (q *queue) push(ev Event) {
+	q.mu.Lock()
+	q.events = append(q.events, ev)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump отдаёт события подписчику по порядку; после отписки закрывает канал.
+func (q *queue) pump(out chan<- Event) {
+	defer close(out)
+	for {
+		q.mu.Lock()
+		if len(q.events) == 0 {
+			q.mu.Unlock()
+			select {
+			case <-q.wake:
+				continue
+			case <-q.done:
+				return
+			}
+		}
+		ev := q.events[0]
+		q.events[0] = Event{}
+		q.events = q.events[1:]
+		q.mu.Unlock()
+		select {
+		case out <- ev:
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// tickKey — ключ контекста для ID тика.
+type tickKey struct{}
+
+// WithTick кладёт ID тика в контекст, чтобы нижние слои публиковали события с ним.
+func WithTick(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, tickKey{}, id)
+}
+
+// TickID достаёт ID тика из контекста (0 — вне тика).
+func TickID(ctx context.Context) int64 {
+	id, _ := ctx.Value(tickKey{}).(int64)
+	return id
+}
@@ -0,0 +1,82 @@
+package bus
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublish_DeliversStableSchema(t *testing.T) {
+	b := New()
+	events, unsubscribe := b.Subscribe(4)
+	defer unsubscribe()
+
+	b.Publish(TypeTickStart, 7, TickStart{Trigger: "timer", Persona: "kawaii"})
+
+	ev := <-events
+	data, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{`"v":1`, `"seq":1`, `"type":"tick.start"`, `"tick_id":7`, `"data":{"trigger":"timer","persona":"kawaii"}`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("event json %s does not contain %s", got, want)
+		}
+	}
+}
+
+func TestPublish_SlowSubscriberDoesNotBlock(t *testing.T) {
+	b := New()
+	slow, unsubscribeSlow := b.Subscribe(1)
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := b.Subscribe(10)
+	defer unsubscribeFast()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 10 {
+			b.Publish(TypeError, 0, Error{Source: "test", Message: "boom"})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Publish blocked on a slow subscriber")
+	}
+
+	if n := len(fast); n != 10 {
+		t.Fatalf("fast subscriber got %d events, want 10", n)
+	}
+	if n := len(slow); n > 1 {
+		t.Fatalf("slow subscriber got %d events, want at most its buffer (1)", n)
+	}
+}
+
+func TestPublish_NilBusIsNoop(t *testing.T) {
+	var b *Bus
+	b.Publish(TypeError, 0, nil)
+}
+
+func TestSubscribeLossless_KeepsEveryEventInOrder(t *testing.T) {
+	b := New()
+	events, unsubscribe := b.SubscribeLossless()
+	defer unsubscribe()
+
+	const n = 2000 // больше любого буфера обычной подписки: никто не читает, пока идёт Publish
+	for i := range n {
+		b.Publish(TypeTickStart, int64(i+1), nil)
+	}
+	for i := range n {
+		ev := <-events
+		if ev.TickID != int64(i+1) {
+			t.Fatalf("event %d has tick_id %d, want %d", i, ev.TickID, i+1)
+		}
+	}
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Fatalf("channel not closed after unsubscribe")
+	}
+}
@@ -41,6 +41,15 @@ func (c *Chat) Drain() []string {
 	return msgs
 }
 
+// Snapshot возвращает копию сообщений без очистки буфера.
+func (c *Chat) Snapshot() []string {
+	c.mu.Lock()
+	msgs := make([]string, len(c.messages))
+	copy(msgs, c.messages)
+	c.mu.Unlock()
+	return msgs
+}
+
 func (c *Chat) Len() int {
 	c.mu.Lock()
 	l := len(c.messages)
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -69,6 +70,27 @@ func (s *DotaStateServer) Start(ctx context.Context) error {
 	return nil
 }
 
+func (s *DotaStateServer) Run(ctx context.Context) error {
+	if !s.running.CompareAndSwap(false, true) {
+		return errors.New("dota-event-server already running")
+	}
+	ln, err := net.Listen("tcp", s.cfg.BindAddr)
+	if err != nil {
+		s.running.Store(false)
+		return err
+	}
+	s.logger.Infow("DotaStateServer listening", "addr", s.cfg.BindAddr, "path", s.cfg.Path)
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.srv.Serve(ln) }()
+	select {
+	case <-ctx.Done():
+		return s.Stop(context.WithoutCancel(ctx))
+	case err := <-errCh:
+		s.running.Store(false)
+		return err
+	}
+}
+
 func (s *DotaStateServer) Stop(ctx context.Context) error {
 	if !s.running.CompareAndSwap(true, false) {
 		return nil
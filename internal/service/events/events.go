@@ -9,6 +9,10 @@ type StateServer interface {
 	// Должен реагировать на отмену контекста и завершать работу.
 	Start(ctx context.Context) error
 
+	// Run слушает адрес и обслуживает запросы до отмены контекста (для супервизора).
+	// Ошибки привязки и сбой сервера возвращаются вызывающему.
+	Run(ctx context.Context) error
+
 	// Stop инициирует graceful shutdown с использованием контекста.
 	Stop(ctx context.Context) error
 
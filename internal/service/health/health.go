@@ -0,0 +1,61 @@
+package health
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Состояния подсистем
+const (
+	StateOK       = "ok"
+	StateDegraded = "degraded"
+	StateDown     = "down"
+	StateDisabled = "disabled"
+)
+
+// Status — состояние одной подсистемы.
+type Status struct {
+	Name   string    `json:"name"`
+	State  string    `json:"state"`
+	Detail string    `json:"detail,omitempty"`
+	Since  time.Time `json:"since"` // время последней смены состояния
+}
+
+// Registry — потокобезопасный реестр состояний подсистем (STT, Twitch, TTS, VTube и т.п.).
+type Registry struct {
+	mu    sync.Mutex
+	items map[string]Status
+}
+
+func New() *Registry {
+	return &Registry{items: map[string]Status{}}
+}
+
+// Set обновляет состояние подсистемы; Since меняется только при смене State.
+func (r *Registry) Set(name, state, detail string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.items[name]
+	if st.State != state {
+		st.Since = time.Now()
+	}
+	st.Name, st.State, st.Detail = name, state, detail
+	r.items[name] = st
+}
+
+// List возвращает состояния всех подсистем, отсортированные по имени.
+func (r *Registry) List() []Status {
+	r.mu.Lock()
+	out := make([]Status, 0, len(r.items))
+	for _, st := range r.items {
+		out = append(out, st)
+	}
+	r.mu.Unlock()
+	slices.SortFunc(out, func(a, b Status) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
@@ -0,0 +1,39 @@
+package image
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+)
+
+// Thumbnail читает JPEG и возвращает уменьшенную до width копию (ближайший сосед, quality=70).
+func Thumbnail(path string, width int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	src, err := jpeg.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	dst := src
+	if b.Dx() > width && width > 0 {
+		height := max(1, b.Dy()*width/b.Dx())
+		rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+		for y := range height {
+			sy := b.Min.Y + y*b.Dy()/height
+			for x := range width {
+				rgba.Set(x, y, src.At(b.Min.X+x*b.Dx()/width, sy))
+			}
+		}
+		dst = rgba
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 70}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry — реестр метрик приложения; отдаётся через Handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// TickDuration — длительность тика по итогу (ok|empty|interrupted|error).
+	TickDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "companion_tick_duration_seconds",
+		Help:    "Tick duration by outcome.",
+		Buckets: []float64{0.5, 1, 2, 5, 10, 20, 30, 60},
+	}, []string{"status"})
+
+	// TicksSkipped — тики, не начатые по причине (paused|overlap|no_input).
+	TicksSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "companion_ticks_skipped_total",
+		Help: "Ticks skipped by reason.",
+	}, []string{"reason"})
+
+	// TicksPreempted — прерванные тики по причине (barge_in|overlap).
+	TicksPreempted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "companion_ticks_preempted_total",
+		Help: "Ticks preempted by reason.",
+	}, []string{"reason"})
+
+	// TicksFailed — тики с ошибкой по источнику (llm|tts|playback).
+	TicksFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "companion_ticks_failed_total",
+		Help: "Failed ticks by reason.",
+	}, []string{"reason"})
+
+	// LLMLatency — время ответа ИИ.
+	LLMLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "companion_llm_latency_seconds",
+		Help:    "LLM response latency.",
+		Buckets: []float64{0.5, 1, 2, 3, 5, 8, 13, 20, 30},
+	})
+
+	// ImageUploadBytes — суммарный размер изображений в одном запросе к ИИ.
+	ImageUploadBytes = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "companion_image_upload_bytes",
+		Help:    "Total size of images sent in one LLM request.",
+		Buckets: prometheus.ExponentialBuckets(64<<10, 2, 8), // 64 KiB .. 8 MiB
+	})
+
+	// TTSLatency — время синтеза по провайдеру и результату (ok|error).
+	TTSLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "companion_tts_latency_seconds",
+		Help:    "TTS synthesis latency by provider and result.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 15},
+	}, []string{"provider", "result"})
+
+	// TTSProviderFailures — сбои провайдеров TTS, после которых цепочка перешла к следующему.
+	TTSProviderFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "companion_tts_provider_failures_total",
+		Help: "TTS provider failures in the fallback chain.",
+	}, []string{"provider"})
+
+	// TTSCacheLookups — обращения к кэшу синтеза по результату (hit|miss).
+	TTSCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "companion_tts_cache_lookups_total",
+		Help: "TTS cache lookups by result.",
+	}, []string{"result"})
+
+	// PlaybackDuration — фактическое время воспроизведения речи.
+	PlaybackDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "companion_playback_seconds",
+		Help:    "Speech playback time.",
+		Buckets: []float64{1, 2, 5, 10, 20, 30, 60},
+	})
+
+	// TwitchMessages — сообщения Twitch-чата по результату (accepted|dropped) и причине дропа.
+	TwitchMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "companion_twitch_messages_total",
+		Help: "Twitch chat messages received, by result and drop reason.",
+	}, []string{"result", "reason"})
+
+	// VTubeTriggerFailures — ошибки отправки эмоций в VTube Studio.
+	VTubeTriggerFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "companion_vtube_trigger_failures_total",
+		Help: "VTube Studio hotkey trigger failures.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		TickDuration, TicksSkipped, TicksPreempted, TicksFailed,
+		LLMLatency, ImageUploadBytes, TTSLatency, TTSProviderFailures, TTSCacheLookups, PlaybackDuration,
+		TwitchMessages, VTubeTriggerFailures,
+	)
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveTTS фиксирует время синтеза провайдера.
+func ObserveTTS(provider string, d time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	TTSLatency.WithLabelValues(provider, result).Observe(d.Seconds())
+}
+
+// RegisterBuffer публикует текущий размер буфера (speech|chat|state) как companion_buffer_size{buffer}.
+// Повторная регистрация того же буфера игнорируется.
+func RegisterBuffer(name string, size func() int) {
+	_ = Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "companion_buffer_size",
+		Help:        "Current number of messages in input buffers.",
+		ConstLabels: prometheus.Labels{"buffer": name},
+	}, func() float64 { return float64(size()) }))
+}
+
+// RegisterTTSCache публикует размер кэша синтеза на диске и долю попаданий с запуска.
+// Повторная регистрация игнорируется.
+func RegisterTTSCache(bytes func() int64, hitRatio func() float64) {
+	_ = Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "companion_tts_cache_bytes",
+		Help: "TTS cache size on disk.",
+	}, func() float64 { return float64(bytes()) }))
+	_ = Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "companion_tts_cache_hit_ratio",
+		Help: "TTS cache hit ratio since start.",
+	}, hitRatio))
+}
@@ -3,7 +3,6 @@ package notify
 import (
 	ttsplayer "OpenAIClient/internal/service/tts/player"
 	"context"
-	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -20,9 +19,10 @@ type SoundNotifier struct {
 	ply     ttsplayer.Player
 }
 
-// NewSoundNotifier создаёт нотификатор. Если путь(и) пустые, будут использованы дефолты:
+// NewSoundNotifier создаёт нотификатор, проигрывающий звуки через общий плеер как эффекты.
+// Если путь(и) пустые, будут использованы дефолты:
 // AI: sound/notification1.mp3, TTS: sound/notification3.mp3 (сначала пытаемся рядом с бинарём).
-func NewSoundNotifier(logger *zap.SugaredLogger, pathAI, pathTTS string) *SoundNotifier {
+func NewSoundNotifier(logger *zap.SugaredLogger, ply ttsplayer.Player, pathAI, pathTTS string) *SoundNotifier {
 	resolve := func(def string) string {
 		// Путь по умолчанию: рядом с бинарём
 		if exe, err := os.Executable(); err == nil {
@@ -47,7 +47,7 @@ func NewSoundNotifier(logger *zap.SugaredLogger, pathAI, pathTTS string) *SoundN
 		logger:  logger,
 		pathAI:  pathAI,
 		pathTTS: pathTTS,
-		ply:     ttsplayer.New(),
+		ply:     ply,
 	}
 }
 
@@ -78,16 +78,13 @@ func (n *SoundNotifier) play(ctx context.Context, path string) error {
 		ext = "mp3" // по умолчанию
 	}
 
-	if err := n.ply.Play(ext, rc); err != nil {
-		if n.logger != nil {
+	// Эффект приглушает речь, если звучит одновременно с ней
+	if err := n.ply.Play(ctx, ext, rc, ttsplayer.Options{Kind: ttsplayer.KindEffect}); err != nil {
+		if n.logger != nil && ctx.Err() == nil {
 			n.logger.Warnw("Не удалось воспроизвести звуковое уведомление", "path", path, "error", err)
 		}
 		return err
 	}
-	// Вторичная проверка отмены (если воспроизведение заняло время и контекст отменили)
-	if err := context.Cause(ctx); err != nil && !errors.Is(err, nil) {
-		return err
-	}
 	return nil
 }
 
@@ -48,6 +48,15 @@ func (s *Speech) Drain() []string {
 	return msgs
 }
 
+// Snapshot возвращает копию сообщений без очистки буфера.
+func (s *Speech) Snapshot() []string {
+	s.mu.Lock()
+	msgs := make([]string, len(s.messages))
+	copy(msgs, s.messages)
+	s.mu.Unlock()
+	return msgs
+}
+
 func (s *Speech) Len() int {
 	s.mu.Lock()
 	l := len(s.messages)
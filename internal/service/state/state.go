@@ -45,6 +45,15 @@ func (s *State) Drain() []string {
 	return msgs
 }
 
+// Snapshot возвращает копию сообщений без очистки буфера.
+func (s *State) Snapshot() []string {
+	s.mu.Lock()
+	msgs := make([]string, len(s.messages))
+	copy(msgs, s.messages)
+	s.mu.Unlock()
+	return msgs
+}
+
 func (s *State) Len() int {
 	s.mu.Lock()
 	l := len(s.messages)
@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/metrics"
+	"OpenAIClient/internal/service/tts"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// entry — запись кэша: файл <key>.<format> в папке кэша.
+type entry struct {
+	format  string
+	size    int64
+	created time.Time // время синтеза (mtime файла) — для TTL
+	used    time.Time // последнее обращение — для LRU
+}
+
+// Store — дисковое хранилище кэша синтеза, одно на всю цепочку провайдеров: общий индекс,
+// общий лимит размера (вытесняются давно не использованные записи любого провайдера) и общие метрики.
+type Store struct {
+	dir      string
+	maxBytes int64
+	ttl      time.Duration
+	logger   *zap.SugaredLogger
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	total   int64
+
+	hits, misses atomic.Int64
+}
+
+// New открывает хранилище в cfg.Dir; файлы прошлых запусков подхватываются сразу.
+func New(cfg config.TTSCacheConfig, logger *zap.SugaredLogger) *Store {
+	s := &Store{
+		dir:      cfg.Dir,
+		maxBytes: int64(cfg.MaxSizeMB) << 20,
+		ttl:      cfg.TTL,
+		logger:   logger,
+		entries:  map[string]*entry{},
+	}
+	s.scan()
+	metrics.RegisterTTSCache(s.size, s.HitRatio)
+	logger.Infow("TTS cache enabled", "dir", s.dir, "entries", len(s.entries), "sizeMB", s.total>>20)
+	return s
+}
+
+// Cache — декоратор tts.Synthesizer одного провайдера: повторные фразы берутся из Store вместо платного синтеза.
+// Ключ — провайдер, его настройки голоса, промпт и текст.
+type Cache struct {
+	next     tts.Synthesizer
+	store    *Store
+	provider string
+	settings string // настройки голоса провайдера (JSON) — часть ключа
+}
+
+// Wrap оборачивает синтезатор провайдера кэшем. settings — настройки голоса (tts.Provider.Settings):
+// при их смене старые записи не подходят.
+func (s *Store) Wrap(next tts.Synthesizer, provider string, settings any) *Cache {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		s.logger.Warnw("TTS cache: provider settings not serializable", "provider", provider, "error", err)
+	}
+	return &Cache{next: next, store: s, provider: provider, settings: string(raw)}
+}
+
+// Synthesize отдаёт аудио из кэша или синтезирует через next и сохраняет результат.
+// Ошибка записи на диск не мешает озвучке — только логируется.
+func (c *Cache) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	s := c.store
+	key := c.key(text, prompt)
+	if format, data, ok := s.get(key); ok {
+		s.hits.Add(1)
+		metrics.TTSCacheLookups.WithLabelValues("hit").Inc()
+		return format, io.NopCloser(bytes.NewReader(data)), nil
+	}
+	s.misses.Add(1)
+	metrics.TTSCacheLookups.WithLabelValues("miss").Inc()
+
+	format, rc, err := c.next.Synthesize(ctx, text, prompt)
+	if err != nil {
+		return format, rc, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return "", nil, fmt.Errorf("tts cache: read audio: %w", err)
+	}
+	if err := s.put(key, format, data); err != nil {
+		s.logger.Warnw("TTS cache write failed", "error", err)
+	}
+	return format, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// HitRatio — доля попаданий с запуска по всем провайдерам (0, пока обращений не было).
+func (s *Store) HitRatio() float64 {
+	hits, misses := s.hits.Load(), s.misses.Load()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+// key — sha256 от провайдера, настроек голоса, промпта и текста.
+func (c *Cache) key(text, prompt string) string {
+	h := sha256.New()
+	for _, part := range []string{c.provider, c.settings, prompt, text} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// get читает запись с диска; просроченная или пропавшая запись удаляется.
+func (s *Store) get(key string) (string, []byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return "", nil, false
+	}
+	if s.ttl > 0 && time.Since(e.created) > s.ttl {
+		s.remove(key, e)
+		return "", nil, false
+	}
+	data, err := os.ReadFile(s.path(key, e.format))
+	if err != nil {
+		s.remove(key, e)
+		return "", nil, false
+	}
+	e.used = time.Now()
+	return e.format, data, true
+}
+
+// put пишет запись атомарно (временный файл + rename) и вытесняет старые записи сверх лимита.
+func (s *Store) put(key, format string, data []byte) error {
+	format = sanitizeFormat(format)
+	if format == "" || int64(len(data)) > s.maxBytes {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(key, format)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.entries[key]; ok {
+		s.total -= old.size
+	}
+	now := time.Now()
+	s.entries[key] = &entry{format: format, size: int64(len(data)), created: now, used: now}
+	s.total += int64(len(data))
+	s.evict()
+	return nil
+}
+
+// evict удаляет давно не использованные записи, пока кэш больше лимита (под mu).
+func (s *Store) evict() {
+	for s.total > s.maxBytes {
+		var oldKey string
+		var oldest *entry
+		for k, e := range s.entries {
+			if oldest == nil || e.used.Before(oldest.used) {
+				oldKey, oldest = k, e
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		s.remove(oldKey, oldest)
+	}
+}
+
+// remove удаляет запись и её файл (под mu).
+func (s *Store) remove(key string, e *entry) {
+	delete(s.entries, key)
+	s.total -= e.size
+	if err := os.Remove(s.path(key, e.format)); err != nil && !os.IsNotExist(err) {
+		s.logger.Warnw("TTS cache remove failed", "key", key, "error", err)
+	}
+}
+
+// scan подхватывает файлы прошлых запусков: просроченные удаляет, остальное ужимает до лимита.
+func (s *Store) scan() {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return // папки ещё нет — создастся при первой записи
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(name, ".tmp") {
+			_ = os.Remove(filepath.Join(s.dir, name)) // недописанный файл упавшего запуска
+			continue
+		}
+		key, format, ok := strings.Cut(name, ".")
+		if !ok || len(key) != sha256.Size*2 {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		e := &entry{format: format, size: info.Size(), created: info.ModTime(), used: info.ModTime()}
+		if s.ttl > 0 && time.Since(e.created) > s.ttl {
+			s.total += e.size
+			s.remove(key, e)
+			continue
+		}
+		s.entries[key] = e
+		s.total += e.size
+	}
+	s.evict()
+}
+
+func (s *Store) size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+func (s *Store) path(key, format string) string {
+	return filepath.Join(s.dir, key+"."+format)
+}
+
+// sanitizeFormat оставляет в формате только [a-z0-9] — он становится расширением файла.
+func sanitizeFormat(format string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, strings.ToLower(format))
+}
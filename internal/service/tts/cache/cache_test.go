@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"OpenAIClient/internal/config"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// stubSynth отдаёт заданные байты и считает вызовы.
+type stubSynth struct {
+	data  []byte
+	calls int
+}
+
+func (s *stubSynth) Synthesize(context.Context, string, string) (string, io.ReadCloser, error) {
+	s.calls++
+	return "mp3", io.NopCloser(bytes.NewReader(s.data)), nil
+}
+
+func newStore(t *testing.T, dir string, maxMB int, ttl time.Duration) *Store {
+	t.Helper()
+	return New(config.TTSCacheConfig{Enabled: true, Dir: dir, MaxSizeMB: maxMB, TTL: ttl}, zap.NewNop().Sugar())
+}
+
+func synth(t *testing.T, c *Cache, text string) []byte {
+	t.Helper()
+	_, rc, err := c.Synthesize(context.Background(), text, "")
+	if err != nil {
+		t.Fatalf("synthesize %q: %v", text, err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+	return data
+}
+
+func TestCache_KeySeparatesProvidersAndSettings(t *testing.T) {
+	store := newStore(t, t.TempDir(), 10, 0)
+	a, b, c := &stubSynth{data: []byte("a")}, &stubSynth{data: []byte("b")}, &stubSynth{data: []byte("c")}
+	ca := store.Wrap(a, "yandex", map[string]string{"voice": "alena"})
+	cb := store.Wrap(b, "google", map[string]string{"voice": "alena"})
+	cc := store.Wrap(c, "yandex", map[string]string{"voice": "filipp"})
+
+	for range 2 {
+		if got := string(synth(t, ca, "Привет")); got != "a" {
+			t.Fatalf("yandex/alena = %q, want a", got)
+		}
+		if got := string(synth(t, cb, "Привет")); got != "b" {
+			t.Fatalf("google = %q, want b", got)
+		}
+		if got := string(synth(t, cc, "Привет")); got != "c" {
+			t.Fatalf("yandex/filipp = %q, want c", got)
+		}
+	}
+	if a.calls != 1 || b.calls != 1 || c.calls != 1 {
+		t.Fatalf("calls = %d/%d/%d, want 1/1/1 (second round from cache)", a.calls, b.calls, c.calls)
+	}
+	if len(store.entries) != 3 {
+		t.Fatalf("entries = %d, want 3 in one shared index", len(store.entries))
+	}
+	if r := store.HitRatio(); r != 0.5 {
+		t.Fatalf("hit ratio = %v, want 0.5 across providers", r)
+	}
+}
+
+func TestCache_TTLExpiry(t *testing.T) {
+	store := newStore(t, t.TempDir(), 10, time.Hour)
+	next := &stubSynth{data: []byte("audio")}
+	c := store.Wrap(next, "yandex", nil)
+
+	synth(t, c, "Привет")
+	for _, e := range store.entries {
+		e.created = time.Now().Add(-2 * time.Hour)
+	}
+	synth(t, c, "Привет")
+	if next.calls != 2 {
+		t.Fatalf("calls = %d, want 2: expired entry must be synthesized again", next.calls)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsedAcrossProviders(t *testing.T) {
+	store := newStore(t, t.TempDir(), 1, 0)
+	clip := bytes.Repeat([]byte{1}, 400<<10) // три клипа не помещаются в 1 МБ
+	a := store.Wrap(&stubSynth{data: clip}, "yandex", nil)
+	b := store.Wrap(&stubSynth{data: clip}, "google", nil)
+
+	synth(t, a, "первый")
+	synth(t, b, "второй")
+	keyFirst, keySecond := a.key("первый", ""), b.key("второй", "")
+	// Первый использован позже второго — вытеснен должен быть второй
+	store.entries[keySecond].used = time.Now().Add(-time.Minute)
+	store.entries[keyFirst].used = time.Now()
+	synth(t, a, "третий")
+
+	if _, ok := store.entries[keySecond]; ok {
+		t.Fatalf("least recently used entry of another provider was not evicted")
+	}
+	if _, ok := store.entries[keyFirst]; !ok {
+		t.Fatalf("recently used entry was evicted")
+	}
+	if _, err := os.Stat(store.path(keySecond, "mp3")); !os.IsNotExist(err) {
+		t.Fatalf("evicted file still on disk: %v", err)
+	}
+	if store.total > store.maxBytes {
+		t.Fatalf("total = %d exceeds limit %d", store.total, store.maxBytes)
+	}
+}
+
+func TestStore_RescansExistingFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := newStore(t, dir, 10, time.Hour)
+	c := first.Wrap(&stubSynth{data: []byte("audio")}, "yandex", nil)
+	synth(t, c, "Привет")
+	key := c.key("Привет", "")
+
+	// Недописанный файл упавшего запуска и просроченная запись удаляются при запуске
+	tmp := filepath.Join(dir, key+".123.tmp")
+	_ = os.WriteFile(tmp, []byte("partial"), 0o644)
+	stale := c.key("старое", "")
+	_ = os.WriteFile(first.path(stale, "mp3"), []byte("old"), 0o644)
+	old := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(first.path(stale, "mp3"), old, old)
+
+	second := newStore(t, dir, 10, time.Hour)
+	next := &stubSynth{data: []byte("other")}
+	if got := string(synth(t, second.Wrap(next, "yandex", nil), "Привет")); got != "audio" || next.calls != 0 {
+		t.Fatalf("after restart got %q with %d calls, want cached audio", got, next.calls)
+	}
+	if second.total != int64(len("audio")) || len(second.entries) != 1 {
+		t.Fatalf("rescan: total=%d entries=%d, want %d/1", second.total, len(second.entries), len("audio"))
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("temp file not removed on rescan")
+	}
+	if _, err := os.Stat(second.path(stale, "mp3")); !os.IsNotExist(err) {
+		t.Fatalf("expired file not removed on rescan")
+	}
+}
@@ -0,0 +1,236 @@
+package chunk
+
+import (
+	"OpenAIClient/internal/service/tts"
+	"OpenAIClient/internal/service/tts/markup"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minRunes — короче этого предложение («Ого!») не синтезируется отдельно, а склеивается со следующим.
+const minRunes = 30
+
+// Split делит текст на куски по предложениям; предложение длиннее maxRunes режется по запятым и пробелам.
+// Стили разметки, открытые в конце куска ([whisper]…), продолжаются в следующем. maxRunes ≤ 0 — без деления.
+func Split(text string, maxRunes int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if maxRunes <= 0 {
+		return []string{text}
+	}
+	var pieces []string
+	for _, s := range sentences(text) {
+		pieces = append(pieces, cut(s, maxRunes)...)
+	}
+
+	// Короткие предложения склеиваем со следующими, пока кусок не станет достаточно длинным
+	var chunks []string
+	for _, p := range pieces {
+		if n := len(chunks); n > 0 {
+			last := chunks[n-1]
+			if utf8.RuneCountInString(last) < minRunes && utf8.RuneCountInString(last)+1+utf8.RuneCountInString(p) <= maxRunes {
+				chunks[n-1] = last + " " + p
+				continue
+			}
+		}
+		chunks = append(chunks, p)
+	}
+
+	// Продолжаем незакрытые стили: "[whisper]Раз. Два.[/whisper]" → "[whisper]Раз." и "[whisper]Два.[/whisper]"
+	var prefix strings.Builder
+	for i, c := range chunks {
+		if i > 0 {
+			chunks[i] = markup.Unclosed(prefix.String()) + c
+		}
+		prefix.WriteString(c + " ")
+	}
+	return chunks
+}
+
+// sentences делит текст после .!?… (с кавычками и скобками за ними), за которыми идёт пробел, и по переводам строк.
+func sentences(text string) []string {
+	var out []string
+	start := 0
+	rs := []rune(text)
+	pos := 0 // байтовая позиция rs[i]
+	for i := 0; i < len(rs); i++ {
+		size := utf8.RuneLen(rs[i])
+		end := -1
+		switch {
+		case rs[i] == '\n':
+			end = pos
+		case strings.ContainsRune(".!?…", rs[i]):
+			j, next := i+1, pos+size
+			for j < len(rs) && strings.ContainsRune(".!?…\"»)", rs[j]) {
+				next += utf8.RuneLen(rs[j])
+				j++
+			}
+			if j == len(rs) || unicode.IsSpace(rs[j]) {
+				end = next
+				pos, i = next, j-1
+				size = 0
+			}
+		}
+		if end >= 0 {
+			if s := strings.TrimSpace(text[start:end]); s != "" {
+				out = append(out, s)
+			}
+			start = end
+		}
+		pos += size
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// cut режет длинное предложение: сначала после , ; : — ближе к пределу, затем по пробелу, в крайнем случае — по пределу.
+// Внутри тегов разметки ([pause 800ms]) не режет.
+func cut(s string, maxRunes int) []string {
+	var out []string
+	for utf8.RuneCountInString(s) > maxRunes {
+		rs := []rune(s)
+		at := lastCut(rs, maxRunes, func(i int) bool {
+			return strings.ContainsRune(",;:—", rs[i]) && i+1 < len(rs) && unicode.IsSpace(rs[i+1])
+		})
+		if at <= 0 {
+			at = lastCut(rs, maxRunes, func(i int) bool { return rs[i] == ' ' })
+		}
+		if at <= 0 {
+			at = maxRunes
+		}
+		out = append(out, strings.TrimSpace(string(rs[:at])))
+		s = strings.TrimSpace(string(rs[at:]))
+	}
+	if s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// lastCut — позиция сразу после последнего подходящего места разреза до предела (вне тегов) или 0.
+func lastCut(rs []rune, maxRunes int, ok func(i int) bool) int {
+	for i := maxRunes - 1; i > 0; i-- {
+		if ok(i) && !insideTag(rs[:i]) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// insideTag — после rs остаётся незакрытая квадратная скобка.
+func insideTag(rs []rune) bool {
+	open := strings.LastIndex(string(rs), "[")
+	return open >= 0 && !strings.Contains(string(rs)[open:], "]")
+}
+
+// SynthFunc синтезирует один кусок (обычно — цепочкой провайдеров). prefer — провайдер, озвучивший первый кусок:
+// его надо пробовать первым, чтобы голос не менялся посреди ответа; для первого куска — пусто.
+type SynthFunc func(ctx context.Context, text, prefer string) (tts.Provider, string, io.ReadCloser, error)
+
+// Result — синтезированный кусок.
+type Result struct {
+	Text     string // текст куска (с разметкой)
+	Provider tts.Provider
+	Format   string
+	RC       io.ReadCloser
+	Err      error
+}
+
+// Synthesize синтезирует куски не более parallel одновременно и отдаёт результаты строго по порядку.
+// Остальные куски запускаются, когда готов первый, и получают его провайдера в prefer: если первый кусок
+// озвучил запасной провайдер, весь ответ звучит его голосом.
+// Канал закрывается после последнего куска или при отмене ctx; невыданные потоки аудио при этом закрываются.
+func Synthesize(ctx context.Context, chunks []string, parallel int, synth SynthFunc) <-chan Result {
+	out := make(chan Result)
+	slots := make([]chan Result, len(chunks))
+	for i := range slots {
+		slots[i] = make(chan Result, 1)
+	}
+
+	var wg sync.WaitGroup
+	launched := make(chan struct{}) // закрывается, когда новые синтезы больше не запускаются
+	sem := make(chan struct{}, max(1, parallel))
+	firstDone := make(chan struct{}) // первый кусок готов, prefer известен
+	var prefer string
+	go func() {
+		defer close(launched)
+		for i, text := range chunks {
+			if i == 1 {
+				select {
+				case <-firstDone:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				provider, format, rc, err := synth(ctx, text, prefer)
+				if i == 0 {
+					prefer = provider.Name
+					close(firstDone)
+				}
+				slots[i] <- Result{Text: text, Provider: provider, Format: format, RC: rc, Err: err}
+			}()
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		for _, slot := range slots {
+			var r Result
+			select {
+			case r = <-slot:
+			case <-ctx.Done():
+				discard(launched, &wg, slots)
+				return
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				closeResult(r)
+				discard(launched, &wg, slots)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// discard дожидается запущенных синтезов и закрывает их невыданные результаты.
+func discard(launched <-chan struct{}, wg *sync.WaitGroup, slots []chan Result) {
+	go func() {
+		<-launched
+		wg.Wait()
+		for _, slot := range slots {
+			select {
+			case r := <-slot:
+				closeResult(r)
+			default:
+			}
+		}
+	}()
+}
+
+func closeResult(r Result) {
+	if r.RC != nil {
+		_ = r.RC.Close()
+	}
+}
@@ -0,0 +1,204 @@
+package chunk
+
+import (
+	"OpenAIClient/internal/service/tts"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	long := strings.Repeat("слово ", 20) // 120 символов без знаков препинания
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want []string
+	}{
+		{
+			name: "sentence boundaries",
+			text: "Первое предложение здесь довольно длинное. Второе тоже не самое короткое! А третье спрашивает?",
+			max:  60,
+			want: []string{"Первое предложение здесь довольно длинное.", "Второе тоже не самое короткое!", "А третье спрашивает?"},
+		},
+		{
+			name: "ellipsis followed by quotes",
+			text: "Он сказал: «Ну что ж, посмотрим…» Потом долго молчал и смотрел в окно.",
+			max:  50,
+			want: []string{"Он сказал: «Ну что ж, посмотрим…»", "Потом долго молчал и смотрел в окно."},
+		},
+		{
+			name: "no cut inside pause tag",
+			text: "Сейчас будет пауза перед важным [pause 800ms] и продолжение фразы",
+			max:  40,
+			want: []string{"Сейчас будет пауза перед важным", "[pause 800ms] и продолжение фразы"},
+		},
+		{
+			name: "unclosed whisper carried into next chunk",
+			text: "[whisper]Это первая тайная фраза для тебя. А это вторая тайная фраза.[/whisper]",
+			max:  50,
+			want: []string{"[whisper]Это первая тайная фраза для тебя.", "[whisper]А это вторая тайная фраза.[/whisper]"},
+		},
+		{
+			name: "short sentence glued to next",
+			text: "Ого! Вот это действительно неожиданный поворот событий.",
+			max:  100,
+			want: []string{"Ого! Вот это действительно неожиданный поворот событий."},
+		},
+		{
+			name: "long sentence cut after comma",
+			text: "Когда я пришёл домой после долгой прогулки, кот уже спал на диване у окна.",
+			max:  50,
+			want: []string{"Когда я пришёл домой после долгой прогулки,", "кот уже спал на диване у окна."},
+		},
+		{
+			name: "cut by space",
+			text: long,
+			max:  50,
+			want: []string{strings.TrimSpace(long[:len("слово ")*8]), strings.TrimSpace(long[:len("слово ")*8]), "слово слово слово слово"},
+		},
+		{
+			name: "no limit",
+			text: "Раз. Два.",
+			max:  0,
+			want: []string{"Раз. Два."},
+		},
+		{
+			name: "empty",
+			text: "   ",
+			max:  50,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.text, tt.max)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Split(%q, %d)\n got %q\nwant %q", tt.text, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+// closeTracker — поток аудио, помнящий, закрыли ли его.
+type closeTracker struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestSynthesize_InOrderWhenChunksFinishOutOfOrder(t *testing.T) {
+	chunks := []string{"a", "b", "c", "d"}
+	delay := map[string]time.Duration{"a": 30 * time.Millisecond, "b": 20 * time.Millisecond, "c": 0, "d": 10 * time.Millisecond}
+	results := Synthesize(context.Background(), chunks, 4, func(ctx context.Context, text, _ string) (tts.Provider, string, io.ReadCloser, error) {
+		time.Sleep(delay[text])
+		return tts.Provider{Name: "p"}, "mp3", io.NopCloser(strings.NewReader(text)), nil
+	})
+	var got []string
+	for r := range results {
+		got = append(got, r.Text)
+		_ = r.RC.Close()
+	}
+	if !reflect.DeepEqual(got, chunks) {
+		t.Fatalf("order = %v, want %v", got, chunks)
+	}
+}
+
+func TestSynthesize_BoundsParallelism(t *testing.T) {
+	var running, peak atomic.Int32
+	chunks := []string{"1", "2", "3", "4", "5", "6"}
+	results := Synthesize(context.Background(), chunks, 2, func(ctx context.Context, text, _ string) (tts.Provider, string, io.ReadCloser, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return tts.Provider{}, "mp3", io.NopCloser(strings.NewReader(text)), nil
+	})
+	count := 0
+	for range results {
+		count++
+	}
+	if count != len(chunks) {
+		t.Fatalf("got %d results, want %d", count, len(chunks))
+	}
+	if p := peak.Load(); p > 2 {
+		t.Fatalf("peak parallel syntheses = %d, want at most 2", p)
+	}
+}
+
+func TestSynthesize_CancelClosesUnreadStreams(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	var streams []*closeTracker
+	chunks := []string{"1", "2", "3", "4", "5"}
+	results := Synthesize(ctx, chunks, 5, func(ctx context.Context, text, _ string) (tts.Provider, string, io.ReadCloser, error) {
+		rc := &closeTracker{Reader: strings.NewReader(text)}
+		mu.Lock()
+		streams = append(streams, rc)
+		mu.Unlock()
+		return tts.Provider{}, "mp3", rc, nil
+	})
+
+	first := <-results
+	time.Sleep(20 * time.Millisecond) // остальные куски успевают синтезироваться и ждут в очереди
+	cancel()
+	for range results {
+		t.Fatalf("result delivered after cancel")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		open := 0
+		for _, rc := range streams {
+			if rc != first.RC && !rc.closed.Load() {
+				open++
+			}
+		}
+		total := len(streams)
+		mu.Unlock()
+		if open == 0 && total == len(chunks) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d unread streams left open after cancel", open, total-1)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSynthesize_PinsRemainingChunksToFirstProvider(t *testing.T) {
+	var mu sync.Mutex
+	prefers := map[string]string{}
+	results := Synthesize(context.Background(), []string{"1", "2", "3"}, 3, func(ctx context.Context, text, prefer string) (tts.Provider, string, io.ReadCloser, error) {
+		mu.Lock()
+		prefers[text] = prefer
+		mu.Unlock()
+		name := "google" // будто основной провайдер упал на первом куске
+		if prefer != "" {
+			name = prefer
+		}
+		return tts.Provider{Name: name}, "mp3", io.NopCloser(strings.NewReader(text)), nil
+	})
+	for r := range results {
+		_ = r.RC.Close()
+	}
+	want := map[string]string{"1": "", "2": "google", "3": "google"}
+	if !reflect.DeepEqual(prefers, want) {
+		t.Fatalf("prefer per chunk = %v, want %v", prefers, want)
+	}
+}
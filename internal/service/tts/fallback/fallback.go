@@ -0,0 +1,129 @@
+package fallback
+
+import (
+	"OpenAIClient/internal/service/metrics"
+	"OpenAIClient/internal/service/tts"
+	"OpenAIClient/internal/service/tts/markup"
+	"OpenAIClient/internal/service/tts/normalize"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// Chain перебирает провайдеров по порядку: при ошибке синтеза переходит к следующему,
+// а упавший провайдер пропускается до конца cooldown.
+type Chain struct {
+	providers []tts.Provider
+	norm      *normalize.Normalizer
+	cooldown  time.Duration
+	logger    *zap.SugaredLogger
+	now       func() time.Time // часы cooldown; в тестах подменяются
+
+	mu        sync.Mutex
+	downUntil map[string]time.Time // провайдер → до какого момента его пропускать
+}
+
+// New создаёт цепочку из провайдеров реестра (tts.Build). norm — нормализация текста (nil — без неё);
+// cooldown — сколько пропускать провайдера после ошибки.
+func New(providers []tts.Provider, norm *normalize.Normalizer, cooldown time.Duration, logger *zap.SugaredLogger) *Chain {
+	return &Chain{providers: providers, norm: norm, cooldown: cooldown, logger: logger, now: time.Now, downUntil: map[string]time.Time{}}
+}
+
+// Synthesize реализует tts.Synthesizer.
+func (c *Chain) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	_, format, rc, err := c.SynthesizeFrom(ctx, text, prompt, "")
+	return format, rc, err
+}
+
+// SynthesizeFrom синтезирует первым доступным провайдером и возвращает его. prefer — провайдер, которого пробовать
+// первым, вне порядка цепочки (куски одного ответа озвучиваются одним голосом); пусто — обычный порядок.
+// Текст нормализуется под каждого провайдера (словарь произношения с его правилами), затем
+// нейтральная разметка переводится в его диалект (Capabilities.Markup).
+// Промпт стиля получают только провайдеры с Capabilities.StylePrompt; провайдер с пределом длины ниже текста пропускается
+// (Google считает байты UTF-8, а не символы: кириллица — два байта на букву).
+// Отказ провайдера от самого запроса (tts.ErrInvalidInput) не ставит его на cooldown.
+// Отмена контекста (barge-in, остановка) не считается сбоем провайдера и сразу прерывает перебор.
+// Если все провайдеры на cooldown, перебираются все — лучше попытаться, чем промолчать.
+func (c *Chain) SynthesizeFrom(ctx context.Context, text, prompt, prefer string) (provider tts.Provider, format string, rc io.ReadCloser, err error) {
+	var errs []error
+	for _, p := range c.candidates(prefer) {
+		in := markup.MapText(text, func(s string) string { return c.norm.Apply(s, p.Name, p.Caps.PlusStress) })
+		in = markup.Render(in, p.Caps.Markup)
+		if n := utf8.RuneCountInString(in); p.Caps.MaxTextLen > 0 && n > p.Caps.MaxTextLen {
+			errs = append(errs, fmt.Errorf("%s: text too long (%d > %d characters)", p.Name, n, p.Caps.MaxTextLen))
+			continue
+		}
+		if n := len(in); p.Caps.MaxTextBytes > 0 && n > p.Caps.MaxTextBytes {
+			errs = append(errs, fmt.Errorf("%s: text too long (%d > %d bytes)", p.Name, n, p.Caps.MaxTextBytes))
+			continue
+		}
+		pr := ""
+		if p.Caps.StylePrompt {
+			pr = prompt
+		}
+		format, rc, err := p.Synthesize(ctx, in, pr)
+		if err == nil {
+			c.markUp(p.Name)
+			return p, format, rc, nil
+		}
+		if ctx.Err() != nil {
+			return p, "", nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+		if errors.Is(err, tts.ErrInvalidInput) {
+			// Провайдер работает, ему не подошёл этот текст: следующий пробует без cooldown для этого
+			c.logger.Warnw("TTS provider rejected text", "provider", p.Name, "error", err)
+			continue
+		}
+		c.markDown(p.Name)
+		metrics.TTSProviderFailures.WithLabelValues(p.Name).Inc()
+		c.logger.Warnw("TTS provider failed", "provider", p.Name, "cooldown", c.cooldown, "error", err)
+	}
+	return tts.Provider{}, "", nil, errors.Join(errs...)
+}
+
+// candidates — провайдеры вне cooldown в порядке цепочки, prefer — первым; если таких нет — все.
+func (c *Chain) candidates(prefer string) []tts.Provider {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	out := make([]tts.Provider, 0, len(c.providers))
+	for _, p := range c.providers {
+		if now.Before(c.downUntil[p.Name]) {
+			continue
+		}
+		if p.Name == prefer {
+			out = append([]tts.Provider{p}, out...)
+			continue
+		}
+		out = append(out, p)
+	}
+	if len(out) == 0 {
+		return c.providers
+	}
+	return out
+}
+
+func (c *Chain) markDown(name string) {
+	if len(c.providers) < 2 {
+		return // пропускать единственного провайдера некуда
+	}
+	c.mu.Lock()
+	c.downUntil[name] = c.now().Add(c.cooldown)
+	c.mu.Unlock()
+}
+
+func (c *Chain) markUp(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.downUntil[name]; ok {
+		delete(c.downUntil, name)
+		c.logger.Infow("TTS provider recovered", "provider", name)
+	}
+}
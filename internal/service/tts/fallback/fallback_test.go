@@ -0,0 +1,169 @@
+package fallback
+
+import (
+	"OpenAIClient/internal/service/tts"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fakeSynth отдаёт err (nil — успех) и запоминает тексты вызовов.
+type fakeSynth struct {
+	err   error
+	calls []string
+}
+
+func (f *fakeSynth) Synthesize(_ context.Context, text, _ string) (string, io.ReadCloser, error) {
+	f.calls = append(f.calls, text)
+	if f.err != nil {
+		return "", nil, f.err
+	}
+	return "mp3", io.NopCloser(strings.NewReader(text)), nil
+}
+
+// clock — подменяемые часы цепочки.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// provider — провайдер без ограничений с фейковым синтезом.
+func provider(name string, s *fakeSynth) tts.Provider {
+	return tts.Provider{Synthesizer: s, Name: name}
+}
+
+func newChain(cooldown time.Duration, providers ...tts.Provider) (*Chain, *clock) {
+	clk := &clock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	c := New(providers, nil, cooldown, zap.NewNop().Sugar())
+	c.now = clk.now
+	return c, clk
+}
+
+// served синтезирует text и возвращает имя провайдера, который его озвучил.
+func served(t *testing.T, c *Chain, text, prefer string) string {
+	t.Helper()
+	p, _, rc, err := c.SynthesizeFrom(context.Background(), text, "", prefer)
+	if err != nil {
+		t.Fatalf("synthesize %q: %v", text, err)
+	}
+	_ = rc.Close()
+	return p.Name
+}
+
+func TestChain_CooldownExpiry(t *testing.T) {
+	a, b := &fakeSynth{err: errors.New("503")}, &fakeSynth{}
+	c, clk := newChain(time.Minute, provider("a", a), provider("b", b))
+
+	if got := served(t, c, "раз", ""); got != "b" {
+		t.Fatalf("first: served by %s, want b", got)
+	}
+	clk.advance(30 * time.Second)
+	if got := served(t, c, "два", ""); got != "b" || len(a.calls) != 1 {
+		t.Fatalf("during cooldown: served by %s, a calls %d; want b and a skipped", got, len(a.calls))
+	}
+	clk.advance(31 * time.Second)
+	a.err = nil
+	if got := served(t, c, "три", ""); got != "a" || len(a.calls) != 2 {
+		t.Fatalf("after cooldown: served by %s, a calls %d; want a retried", got, len(a.calls))
+	}
+	if len(c.downUntil) != 0 {
+		t.Fatalf("recovered provider still marked down: %v", c.downUntil)
+	}
+}
+
+func TestChain_AllDownTriesAll(t *testing.T) {
+	a, b := &fakeSynth{err: errors.New("a down")}, &fakeSynth{err: errors.New("b down")}
+	c, _ := newChain(time.Minute, provider("a", a), provider("b", b))
+
+	_, _, _, err := c.SynthesizeFrom(context.Background(), "раз", "", "")
+	if err == nil || !strings.Contains(err.Error(), "a down") || !strings.Contains(err.Error(), "b down") {
+		t.Fatalf("err = %v, want both failures joined", err)
+	}
+	b.err = nil
+	if got := served(t, c, "два", ""); got != "b" || len(a.calls) != 2 {
+		t.Fatalf("all on cooldown: served by %s, a calls %d; want every provider tried", got, len(a.calls))
+	}
+}
+
+func TestChain_InvalidInputDoesNotStartCooldown(t *testing.T) {
+	a, b := &fakeSynth{err: fmt.Errorf("bad ssml: %w", tts.ErrInvalidInput)}, &fakeSynth{}
+	c, _ := newChain(time.Minute, provider("a", a), provider("b", b))
+
+	for i := range 2 {
+		if got := served(t, c, "текст", ""); got != "b" {
+			t.Fatalf("call %d: served by %s, want b", i, got)
+		}
+	}
+	if len(a.calls) != 2 {
+		t.Fatalf("a calls = %d, want 2: rejected input must not put provider on cooldown", len(a.calls))
+	}
+	if len(c.downUntil) != 0 {
+		t.Fatalf("downUntil = %v, want empty", c.downUntil)
+	}
+}
+
+func TestChain_Prefer(t *testing.T) {
+	a, b, d := &fakeSynth{}, &fakeSynth{}, &fakeSynth{}
+	c, clk := newChain(time.Minute, provider("a", a), provider("b", b), provider("d", d))
+
+	if got := served(t, c, "раз", ""); got != "a" {
+		t.Fatalf("no prefer: served by %s, want a", got)
+	}
+	if got := served(t, c, "два", "d"); got != "d" {
+		t.Fatalf("prefer d: served by %s, want d", got)
+	}
+	if got := served(t, c, "три", "unknown"); got != "a" {
+		t.Fatalf("prefer unknown: served by %s, want a", got)
+	}
+	// Предпочтённый на cooldown — обычный порядок
+	c.markDown("d")
+	clk.advance(time.Second)
+	if got := served(t, c, "четыре", "d"); got != "a" {
+		t.Fatalf("prefer d on cooldown: served by %s, want a", got)
+	}
+}
+
+func TestChain_LengthLimitSkips(t *testing.T) {
+	runes, octets, free := &fakeSynth{}, &fakeSynth{}, &fakeSynth{}
+	c, _ := newChain(time.Minute,
+		tts.Provider{Synthesizer: runes, Name: "runes", Caps: tts.Capabilities{MaxTextLen: 5}},
+		tts.Provider{Synthesizer: octets, Name: "octets", Caps: tts.Capabilities{MaxTextBytes: 10}},
+		provider("free", free),
+	)
+	tests := []struct {
+		text, want string
+	}{
+		{"привет!", "free"},  // 7 символов, 13 байт: длиннее обоих пределов
+		{"abcdef", "octets"}, // 6 символов, 6 байт: превышен только предел символов
+		{"ёжики", "runes"},   // 5 символов, 10 байт: ровно на пределе; пропуски не ставят cooldown
+	}
+	for _, tt := range tests {
+		if got := served(t, c, tt.text, ""); got != tt.want {
+			t.Fatalf("%q: served by %s, want %s", tt.text, got, tt.want)
+		}
+	}
+	if len(runes.calls) != 1 || len(octets.calls) != 1 {
+		t.Fatalf("calls runes=%d octets=%d, want 1/1: over-limit text must not reach provider", len(runes.calls), len(octets.calls))
+	}
+}
+
+func TestChain_CancelStopsWithoutCooldown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a, b := &fakeSynth{}, &fakeSynth{}
+	a.err = context.Canceled
+	cancel()
+	c, _ := newChain(time.Minute, provider("a", a), provider("b", b))
+
+	if _, _, _, err := c.SynthesizeFrom(ctx, "раз", "", ""); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(b.calls) != 0 || len(c.downUntil) != 0 {
+		t.Fatalf("after cancel: b calls %d, down %v; want no fallback and no cooldown", len(b.calls), c.downUntil)
+	}
+}
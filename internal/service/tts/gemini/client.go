@@ -2,6 +2,8 @@ package gemini
 
 import (
 	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/metrics"
+	"OpenAIClient/internal/service/tts"
 	"bytes"
 	"context"
 	"encoding/base64"
@@ -23,11 +25,12 @@ const defaultEndpoint = "https://texttospeech.googleapis.com/v1beta1/text:synthe
 // Client реализует синтез речи через Cloud Text-to-Speech: Gemini‑TTS.
 type Client struct {
 	http   *http.Client
+	cfg    config.GeminiTTSConfig
 	logger *zap.SugaredLogger
 }
 
-func New(logger *zap.SugaredLogger) *Client {
-	return &Client{http: http.DefaultClient, logger: logger}
+func New(cfg config.GeminiTTSConfig, logger *zap.SugaredLogger) *Client {
+	return &Client{http: http.DefaultClient, cfg: cfg, logger: logger}
 }
 
 // requestPayload — максимально нейтральная структура, покрывающая input.prompt и voice.model_name.
@@ -57,12 +60,17 @@ type jsonAudioResponse struct {
 	AudioContent string `json:"audioContent"`
 }
 
-// Synthesize выполняет запрос к Gemini‑TTS и возвращает аудио. cfg должен быть config.GeminiTTSConfig.
-func (c *Client) Synthesize(ctx context.Context, text string, prompt string, cfg any) (string, io.ReadCloser, error) {
-	gc, ok := cfg.(config.GeminiTTSConfig)
-	if !ok {
-		return "", nil, errors.New("gemini tts: unexpected config type")
-	}
+// Synthesize выполняет запрос к Gemini‑TTS и возвращает аудио.
+func (c *Client) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	start := time.Now()
+	format, rc, err := c.synthesize(ctx, text, prompt)
+	metrics.ObserveTTS("gemini", time.Since(start), err)
+	return format, rc, err
+}
+
+// synthesize — сам запрос к провайдеру; Synthesize оборачивает его метриками.
+func (c *Client) synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	gc := c.cfg
 	// Валидация входа: Cloud TTS ожидает text или ssml. Пустой ввод приведёт к 400.
 	if strings.TrimSpace(text) == "" {
 		return "", nil, errors.New("gemini tts: empty input text — provide -text or non-empty SSML")
@@ -137,7 +145,7 @@ func (c *Client) Synthesize(ctx context.Context, text string, prompt string, cfg
 		if len(b) == 0 {
 			b = []byte(resp.Status)
 		}
-		return "", nil, fmt.Errorf("gemini tts error: status=%d, body=%s", resp.StatusCode, strings.TrimSpace(string(b)))
+		return "", nil, tts.StatusError(resp.StatusCode, fmt.Errorf("gemini tts error: status=%d, body=%s", resp.StatusCode, strings.TrimSpace(string(b))))
 	}
 
 	// JSON с base64 полем audioContent
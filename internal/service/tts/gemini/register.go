@@ -0,0 +1,36 @@
+package gemini
+
+import (
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/tts"
+	"OpenAIClient/internal/service/tts/markup"
+	"os"
+	"strings"
+)
+
+func init() {
+	tts.Register(tts.Spec[config.GeminiTTSConfig]{
+		Name:     "gemini",
+		Caps:     tts.Capabilities{Formats: []string{"mp3"}, Markup: markup.Gemini, StylePrompt: true},
+		Settings: func(cfg *config.Config) config.GeminiTTSConfig { return cfg.GeminiTTS },
+		New: func(c config.GeminiTTSConfig, deps tts.Deps) tts.Synthesizer {
+			return New(c, deps.Logger)
+		},
+		Validate: func(config.GeminiTTSConfig) []string {
+			// Gemini берёт учётные данные Google по умолчанию (ADC); файл проверяем, только если он задан явно
+			if p := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); p != "" {
+				if msg := config.FileProblem("GOOGLE_APPLICATION_CREDENTIALS", p, ""); msg != "" {
+					return []string{msg}
+				}
+			}
+			return nil
+		},
+		// GEMINI_TTS_INPUT_TYPE=ssml — SSML, иначе собственные теги Gemini-TTS в тексте
+		Markup: func(c config.GeminiTTSConfig) markup.Dialect {
+			if strings.EqualFold(strings.TrimSpace(c.InputType), "ssml") {
+				return markup.SSML
+			}
+			return markup.Gemini
+		},
+	})
+}
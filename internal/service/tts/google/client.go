@@ -2,9 +2,11 @@ package google
 
 import (
 	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/metrics"
+	"OpenAIClient/internal/service/tts"
 	"bytes"
 	"context"
-	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"time"
@@ -12,23 +14,31 @@ import (
 	gctts "cloud.google.com/go/texttospeech/apiv1"
 	ttspb "cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Client реализует синтез речи через Google Cloud Text-to-Speech.
 type Client struct {
+	cfg    config.GoogleTTSConfig
 	logger *zap.SugaredLogger
 }
 
-func New(logger *zap.SugaredLogger) *Client {
-	return &Client{logger: logger}
+func New(cfg config.GoogleTTSConfig, logger *zap.SugaredLogger) *Client {
+	return &Client{cfg: cfg, logger: logger}
 }
 
-// Synthesize выполняет запрос к Google TTS и возвращает аудио. cfg должен быть config.GoogleTTSConfig.
-func (c *Client) Synthesize(ctx context.Context, text string, _ string, cfg any) (string, io.ReadCloser, error) {
-	gc, ok := cfg.(config.GoogleTTSConfig)
-	if !ok {
-		return "", nil, errors.New("google tts: unexpected config type")
-	}
+// Synthesize выполняет запрос к Google TTS и возвращает аудио.
+func (c *Client) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	start := time.Now()
+	format, rc, err := c.synthesize(ctx, text, prompt)
+	metrics.ObserveTTS("google", time.Since(start), err)
+	return format, rc, err
+}
+
+// synthesize — сам запрос к провайдеру; Synthesize оборачивает его метриками.
+func (c *Client) synthesize(ctx context.Context, text string, _ string) (string, io.ReadCloser, error) {
+	gc := c.cfg
 
 	// Создаём клиента SDK
 	ttsClient, err := gctts.NewClient(ctx)
@@ -37,10 +47,10 @@ func (c *Client) Synthesize(ctx context.Context, text string, _ string, cfg any)
 	}
 	defer ttsClient.Close()
 
-	// Определяем тип входа (text|ssml)
+	// Определяем тип входа (text|ssml); без явного выбора SSML узнаём по <speak> (его даёт перевод разметки)
 	var input *ttspb.SynthesisInput
 	it := strings.ToLower(strings.TrimSpace(gc.InputType))
-	if it == "ssml" {
+	if it == "ssml" || (it == "" && strings.HasPrefix(strings.TrimSpace(text), "<speak")) {
 		input = &ttspb.SynthesisInput{InputSource: &ttspb.SynthesisInput_Ssml{Ssml: text}}
 	} else {
 		input = &ttspb.SynthesisInput{InputSource: &ttspb.SynthesisInput_Text{Text: text}}
@@ -66,6 +76,9 @@ func (c *Client) Synthesize(ctx context.Context, text string, _ string, cfg any)
 	started := time.Now()
 	resp, err := ttsClient.SynthesizeSpeech(ctx, req)
 	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return "", nil, fmt.Errorf("%w: %w", tts.ErrInvalidInput, err)
+		}
 		return "", nil, err
 	}
 	if c.logger != nil {
@@ -0,0 +1,32 @@
+package google
+
+import (
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/tts"
+	"OpenAIClient/internal/service/tts/markup"
+	"strings"
+)
+
+func init() {
+	tts.Register(tts.Spec[config.GoogleTTSConfig]{
+		Name:     "google",
+		Caps:     tts.Capabilities{Formats: []string{"mp3"}, Markup: markup.SSML, MaxTextBytes: 5000},
+		Settings: func(cfg *config.Config) config.GoogleTTSConfig { return cfg.GoogleTTS },
+		New: func(c config.GoogleTTSConfig, deps tts.Deps) tts.Synthesizer {
+			return New(c, deps.Logger)
+		},
+		Validate: func(c config.GoogleTTSConfig) []string {
+			if msg := config.FileProblem("GOOGLE_APPLICATION_CREDENTIALS", c.CredentialsPath, "required for TTS provider google"); msg != "" {
+				return []string{msg}
+			}
+			return nil
+		},
+		// GOOGLE_TTS_INPUT_TYPE=text — только простой текст, разметка вырезается
+		Markup: func(c config.GoogleTTSConfig) markup.Dialect {
+			if strings.EqualFold(strings.TrimSpace(c.InputType), "text") {
+				return markup.Plain
+			}
+			return markup.SSML
+		},
+	})
+}
@@ -0,0 +1,175 @@
+package httptts
+
+import (
+	"OpenAIClient/internal/service/metrics"
+	"OpenAIClient/internal/service/tts"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Плейсхолдеры шаблонов URL и тела запроса
+const (
+	placeholderText   = "{text}"
+	placeholderPrompt = "{prompt}"
+)
+
+// Client реализует синтез речи через произвольный HTTP-сервер (Silero, Coqui, XTTS):
+// адрес, метод, заголовки, шаблон тела и разбор ответа задаются в конфиге.
+type Client struct {
+	http   *http.Client
+	cfg    Config
+	logger *zap.SugaredLogger
+}
+
+func New(cfg Config, logger *zap.SugaredLogger) *Client {
+	return &Client{http: http.DefaultClient, cfg: cfg, logger: logger}
+}
+
+// Synthesize выполняет запрос к серверу и возвращает аудио.
+func (c *Client) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	start := time.Now()
+	format, rc, err := c.synthesize(ctx, text, prompt)
+	metrics.ObserveTTS("http", time.Since(start), err)
+	return format, rc, err
+}
+
+// synthesize — сам запрос к серверу; Synthesize оборачивает его метриками.
+func (c *Client) synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	hc := c.cfg
+	if strings.TrimSpace(text) == "" {
+		return "", nil, errors.New("http tts: empty input text")
+	}
+	if hc.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hc.Timeout)
+		defer cancel()
+	}
+
+	// Текст в URL всегда экранируется как параметр запроса
+	target := fill(hc.URL, text, prompt, url.QueryEscape)
+	var body io.Reader
+	contentType := ""
+	switch strings.ToLower(strings.TrimSpace(hc.BodyType)) {
+	case "json":
+		body = strings.NewReader(fill(hc.Body, text, prompt, jsonEscape))
+		contentType = "application/json"
+	case "form":
+		body = strings.NewReader(fill(hc.Body, text, prompt, url.QueryEscape))
+		contentType = "application/x-www-form-urlencoded"
+	case "", "none":
+	default:
+		return "", nil, fmt.Errorf("http tts: unknown body type %q (json|form|none)", hc.BodyType)
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(hc.Method))
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return "", nil, fmt.Errorf("http tts: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for _, h := range hc.Headers {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok {
+			return "", nil, fmt.Errorf("http tts: header %q: expected \"Name: value\"", h)
+		}
+		req.Header.Set(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("http tts: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return "", nil, tts.StatusError(resp.StatusCode, fmt.Errorf("http tts error: status=%d, body=%s", resp.StatusCode, strings.TrimSpace(string(b))))
+	}
+
+	// Ответ читаем целиком: таймаут запроса действует только внутри synthesize
+	var audio []byte
+	switch strings.ToLower(strings.TrimSpace(hc.Response)) {
+	case "", "raw":
+		audio, err = io.ReadAll(resp.Body)
+	case "json":
+		audio, err = audioFromJSON(resp.Body, hc.AudioField)
+	default:
+		return "", nil, fmt.Errorf("http tts: unknown response type %q (raw|json)", hc.Response)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("http tts: %w", err)
+	}
+	if len(audio) == 0 {
+		return "", nil, errors.New("http tts: empty audio in response")
+	}
+	format := strings.ToLower(strings.TrimSpace(hc.Format))
+	if c.logger != nil {
+		c.logger.Debugw("HTTP TTS response", "url", hc.URL, "bytes", len(audio), "format", format)
+	}
+	return format, io.NopCloser(bytes.NewReader(audio)), nil
+}
+
+// fill подставляет текст и промпт в шаблон, экранируя их под формат шаблона.
+func fill(tmpl, text, prompt string, escape func(string) string) string {
+	return strings.NewReplacer(placeholderText, escape(text), placeholderPrompt, escape(prompt)).Replace(tmpl)
+}
+
+// jsonEscape экранирует строку для вставки внутрь JSON-строки (шаблон содержит кавычки сам: "text": "{text}").
+func jsonEscape(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b[1 : len(b)-1])
+}
+
+// audioFromJSON достаёт base64-аудио по пути вида "audio" или "result.0.audio_base64".
+func audioFromJSON(r io.Reader, path string) ([]byte, error) {
+	var v any
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return nil, fmt.Errorf("decode json response: %w", err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("audio field %q: no element %q", path, key)
+			}
+			v = node[i]
+		default:
+			v = nil
+		}
+		if v == nil {
+			return nil, fmt.Errorf("audio field %q not found in response", path)
+		}
+	}
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return nil, fmt.Errorf("audio field %q is not a base64 string", path)
+	}
+	// Data URL (data:audio/wav;base64,...) — отрезаем префикс
+	if _, data, ok := strings.Cut(s, ";base64,"); ok {
+		s = data
+	}
+	audio, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("audio field %q: %w", path, err)
+	}
+	return audio, nil
+}
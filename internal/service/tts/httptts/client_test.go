@@ -0,0 +1,129 @@
+package httptts
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/caarlos0/env/v6"
+)
+
+func TestJSONEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"привет", "привет"},
+		{`он сказал "да"`, `он сказал \"да\"`},
+		{`C:\path`, `C:\\path`},
+		{"строка\nвторая\tтаб", `строка\nвторая\tтаб`},
+		{"<b> & </b>", `\u003cb\u003e \u0026 \u003c/b\u003e`},
+	}
+	for _, tt := range tests {
+		got := jsonEscape(tt.in)
+		if got != tt.want {
+			t.Errorf("jsonEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		// В шаблоне "...{text}..." результат — корректная JSON-строка с исходным текстом
+		var back string
+		if err := json.Unmarshal([]byte(`"`+got+`"`), &back); err != nil || back != tt.in {
+			t.Errorf("jsonEscape(%q) does not round-trip: %q, %v", tt.in, back, err)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	tests := []struct {
+		name, tmpl, text, prompt string
+		escape                   func(string) string
+		want                     string
+	}{
+		{
+			"json body", `{"text": "{text}", "style": "{prompt}"}`, `"Ура!"` + "\n", "весело",
+			jsonEscape, `{"text": "\"Ура!\"\n", "style": "весело"}`,
+		},
+		{
+			"query", "http://127.0.0.1:8000/tts?text={text}&speaker=xenia", "a b&c=d", "",
+			url.QueryEscape, "http://127.0.0.1:8000/tts?text=a+b%26c%3Dd&speaker=xenia",
+		},
+		{"form", "text={text}&prompt={prompt}", "да/нет", "", url.QueryEscape, "text=%D0%B4%D0%B0%2F%D0%BD%D0%B5%D1%82&prompt="},
+		{"placeholder in text not expanded", `{"text": "{text}"}`, "{prompt}", "секрет", jsonEscape, `{"text": "{prompt}"}`},
+		{"repeated", "{text} {text}", "x", "", jsonEscape, "x x"},
+		{"no placeholders", `{"speaker": "xenia"}`, "x", "y", jsonEscape, `{"speaker": "xenia"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fill(tt.tmpl, tt.text, tt.prompt, tt.escape); got != tt.want {
+				t.Fatalf("fill = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudioFromJSON(t *testing.T) {
+	audio := []byte("RIFF....WAVE")
+	b64 := base64.StdEncoding.EncodeToString(audio)
+	tests := []struct {
+		name, body, path string
+		wantErr          string // пусто — ожидается audio
+	}{
+		{"top level", `{"audio": "` + b64 + `"}`, "audio", ""},
+		{"nested with index", `{"data": [{"wav": "` + b64 + `"}]}`, "data.0.wav", ""},
+		{"data url", `{"audio": "data:audio/wav;base64,` + b64 + `"}`, "audio", ""},
+		{"missing field", `{"audio": "` + b64 + `"}`, "result", `audio field "result" not found`},
+		{"index out of range", `{"data": []}`, "data.0.wav", `no element "0"`},
+		{"path through scalar", `{"data": "x"}`, "data.wav", "not found"},
+		{"not a string", `{"audio": 42}`, "audio", "not a base64 string"},
+		{"empty string", `{"audio": ""}`, "audio", "not a base64 string"},
+		{"bad base64", `{"audio": "%%%"}`, "audio", `audio field "audio"`},
+		{"not json", `<html>`, "audio", "decode json response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := audioFromJSON(strings.NewReader(tt.body), tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || string(got) != string(audio) {
+				t.Fatalf("got %q, %v; want %q", got, err, audio)
+			}
+		})
+	}
+}
+
+func TestHeadersFromEnv(t *testing.T) {
+	var cfg Config
+	err := env.Parse(&cfg, env.Options{Environment: map[string]string{
+		"HTTP_TTS_HEADERS": "Authorization: Bearer k|Accept: audio/wav;q=0.9, */*;q=0.1",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Authorization: Bearer k", "Accept: audio/wav;q=0.9, */*;q=0.1"}
+	if !reflect.DeepEqual(cfg.Headers, want) {
+		t.Fatalf("headers = %q, want %q", cfg.Headers, want)
+	}
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte("wav"))
+	}))
+	defer srv.Close()
+	c := New(Config{URL: srv.URL, Method: "POST", Headers: cfg.Headers, BodyType: "json", Body: `{"text": "{text}"}`, Format: "wav"}, nil)
+	_, rc, err := c.Synthesize(context.Background(), "привет", "")
+	if err != nil {
+		t.Fatalf("synthesize: %v", err)
+	}
+	_, _ = io.ReadAll(rc)
+	if got.Get("Authorization") != "Bearer k" || got.Get("Accept") != "audio/wav;q=0.9, */*;q=0.1" {
+		t.Fatalf("request headers = %v", got)
+	}
+}
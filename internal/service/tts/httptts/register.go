@@ -0,0 +1,81 @@
+package httptts
+
+import (
+	"OpenAIClient/internal/service/tts"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config — синтез через свой HTTP-сервер (Silero, Coqui, XTTS) без кода под каждый API. В YAML — блок http_tts.
+// В URL и теле подставляются {text} и {prompt}, экранированные под формат (query, JSON-строка, form).
+type Config struct {
+	URL        string        `env:"HTTP_TTS_URL" yaml:"url"`
+	Method     string        `env:"HTTP_TTS_METHOD" yaml:"method"`                    // POST (по умолчанию), GET...
+	Headers    []string      `env:"HTTP_TTS_HEADERS" envSeparator:"|" yaml:"headers"` // "Имя: значение"; в .env через '|' (';' бывает в значениях)
+	BodyType   string        `env:"HTTP_TTS_BODY_TYPE" yaml:"body_type"`              // json|form|none
+	Body       string        `env:"HTTP_TTS_BODY" yaml:"body"`                        // Шаблон тела, напр. {"text": "{text}", "speaker": "xenia"}
+	Response   string        `env:"HTTP_TTS_RESPONSE" yaml:"response"`                // raw — аудио в теле; json — base64 в поле AudioField
+	AudioField string        `env:"HTTP_TTS_AUDIO_FIELD" yaml:"audio_field"`          // Путь к полю через точку: audio, data.0.wav
+	Format     string        `env:"HTTP_TTS_FORMAT" yaml:"format"`                    // mp3|wav|ogg|opus|flac
+	Timeout    time.Duration `env:"HTTP_TTS_TIMEOUT" yaml:"timeout"`                  // Предел одного запроса
+}
+
+func init() {
+	tts.Register(tts.Spec[Config]{
+		Name: "http",
+		Caps: tts.Capabilities{Formats: []string{"mp3", "wav", "ogg", "flac"}, StylePrompt: true},
+		Key:  "http_tts",
+		Defaults: Config{
+			Method:   "POST",
+			BodyType: "json",
+			Body:     `{"text": "{text}"}`,
+			Response: "raw",
+			Format:   "wav",
+			Timeout:  30 * time.Second,
+		},
+		New: func(c Config, deps tts.Deps) tts.Synthesizer {
+			return New(c, deps.Logger)
+		},
+		Validate: validate,
+		Secrets:  []string{"HTTP_TTS_HEADERS"}, // обычно там ключ API
+	})
+}
+
+// validate проверяет настройки HTTP TTS (провайдер http в цепочке).
+func validate(h Config) []string {
+	var problems []string
+	add := func(format string, args ...any) { problems = append(problems, fmt.Sprintf(format, args...)) }
+	if u, err := url.Parse(strings.ReplaceAll(h.URL, placeholderText, "x")); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("HTTP_TTS_URL: invalid URL %q (example: http://127.0.0.1:8000/tts)", h.URL)
+	}
+	switch strings.ToLower(strings.TrimSpace(h.BodyType)) {
+	case "json", "form", "none", "":
+	default:
+		add("HTTP_TTS_BODY_TYPE: unknown value %q (json|form|none)", h.BodyType)
+	}
+	switch strings.ToLower(strings.TrimSpace(h.Response)) {
+	case "raw", "":
+	case "json":
+		if strings.TrimSpace(h.AudioField) == "" {
+			add("HTTP_TTS_AUDIO_FIELD: required for HTTP_TTS_RESPONSE=json")
+		}
+	default:
+		add("HTTP_TTS_RESPONSE: unknown value %q (raw|json)", h.Response)
+	}
+	switch strings.ToLower(strings.TrimSpace(h.Format)) {
+	case "mp3", "wav", "ogg", "opus", "flac":
+	default:
+		add("HTTP_TTS_FORMAT: unsupported value %q (mp3|wav|ogg|opus|flac)", h.Format)
+	}
+	if h.Timeout < 0 {
+		add("HTTP_TTS_TIMEOUT: must not be negative, got %s", h.Timeout)
+	}
+	for _, header := range h.Headers {
+		if !strings.Contains(header, ":") {
+			add("HTTP_TTS_HEADERS: %q: expected \"Name: value\"", header)
+		}
+	}
+	return problems
+}
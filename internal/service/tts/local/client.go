@@ -0,0 +1,198 @@
+package local
+
+import (
+	"OpenAIClient/internal/service/metrics"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Плейсхолдеры шаблона команды
+const (
+	placeholderText = "{text}" // текст аргументом; без него текст подаётся в stdin
+	placeholderOut  = "{out}"  // путь к временному WAV; без него аудио читается из stdout
+)
+
+// Client реализует офлайн-синтез речи локальной программой (Piper, RHVoice, espeak-ng) в отдельном процессе.
+// Одновременно запускается не больше LOCAL_TTS_CONCURRENCY процессов.
+type Client struct {
+	cfg    Config
+	logger *zap.SugaredLogger
+	sem    chan struct{}
+}
+
+// New создаёт провайдера; cfg.Concurrency — лимит одновременных процессов (минимум 1).
+func New(cfg Config, logger *zap.SugaredLogger) *Client {
+	return &Client{cfg: cfg, logger: logger, sem: make(chan struct{}, max(1, cfg.Concurrency))}
+}
+
+// Synthesize запускает команду и возвращает WAV.
+func (c *Client) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	start := time.Now()
+	format, rc, err := c.synthesize(ctx, text, prompt)
+	metrics.ObserveTTS("local", time.Since(start), err)
+	return format, rc, err
+}
+
+// synthesize — сам запуск движка; Synthesize оборачивает его метриками.
+func (c *Client) synthesize(ctx context.Context, text string, _ string) (string, io.ReadCloser, error) {
+	lc := c.cfg
+	if strings.TrimSpace(text) == "" {
+		return "", nil, errors.New("local tts: empty input text")
+	}
+	tmpl, err := splitCommand(lc.Command)
+	if err != nil {
+		return "", nil, fmt.Errorf("local tts: %w", err)
+	}
+	if len(tmpl) == 0 {
+		return "", nil, errors.New("local tts: empty command (set LOCAL_TTS_COMMAND)")
+	}
+
+	// Очередь на запуск: ждём свободный слот, пока жив контекст тика
+	select {
+	case c.sem <- struct{}{}:
+		defer func() { <-c.sem }()
+	case <-ctx.Done():
+		return "", nil, ctx.Err()
+	}
+	if lc.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lc.Timeout)
+		defer cancel()
+	}
+
+	// Временный файл — только если шаблон просит {out}
+	var outPath string
+	if strings.Contains(lc.Command, placeholderOut) {
+		f, err := os.CreateTemp("", "companion-tts-*.wav")
+		if err != nil {
+			return "", nil, fmt.Errorf("local tts: %w", err)
+		}
+		outPath = f.Name()
+		_ = f.Close()
+		defer os.Remove(outPath)
+	}
+
+	args := make([]string, len(tmpl))
+	textInArgs := false
+	for i, a := range tmpl {
+		if strings.Contains(a, placeholderText) {
+			textInArgs = true
+			// Текст ответа (в том числе из чата) не должен стать опцией движка: "-o файл" → " -o файл",
+			// если в шаблоне перед ним нет "--"
+			if strings.HasPrefix(a, placeholderText) && strings.HasPrefix(text, "-") && (i == 0 || tmpl[i-1] != "--") {
+				a = " " + a
+			}
+			a = strings.ReplaceAll(a, placeholderText, text)
+		}
+		args[i] = strings.ReplaceAll(a, placeholderOut, outPath)
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.WaitDelay = time.Second // не ждать вечно дочерние процессы, держащие stdout
+	if !textInArgs {
+		cmd.Stdin = strings.NewReader(text)
+	}
+	var stdout bytes.Buffer
+	stderr := &limitedBuffer{limit: 4 << 10}
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+
+	started := time.Now()
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", nil, fmt.Errorf("local tts: %s: %w", filepath.Base(args[0]), ctx.Err())
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", nil, fmt.Errorf("local tts: %s: %w: %s", filepath.Base(args[0]), err, msg)
+		}
+		return "", nil, fmt.Errorf("local tts: %s: %w", filepath.Base(args[0]), err)
+	}
+
+	audio := stdout.Bytes()
+	if outPath != "" {
+		if audio, err = os.ReadFile(outPath); err != nil {
+			return "", nil, fmt.Errorf("local tts: read output: %w", err)
+		}
+	}
+	if len(audio) == 0 {
+		return "", nil, fmt.Errorf("local tts: %s produced no audio", filepath.Base(args[0]))
+	}
+	if c.logger != nil {
+		c.logger.Debugw("Local TTS done", "engine", filepath.Base(args[0]), "bytes", len(audio), "elapsed", time.Since(started))
+	}
+	return "wav", io.NopCloser(bytes.NewReader(audio)), nil
+}
+
+// splitCommand разбивает шаблон на аргументы по пробелам с учётом кавычек "..." и '...'.
+// Обратный слеш не экранирует — пути Windows остаются как есть.
+func splitCommand(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in command")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// CommandPath возвращает исполняемый файл из шаблона команды — для проверки конфига.
+func CommandPath(command string) (string, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+	return exec.LookPath(args[0])
+}
+
+// limitedBuffer хранит первые limit байт (stderr движка для сообщения об ошибке).
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string { return b.buf.String() }
@@ -0,0 +1,47 @@
+package local
+
+import (
+	"OpenAIClient/internal/service/tts"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config — офлайн-синтез локальной программой (Piper, RHVoice, espeak-ng), выдающей WAV. В YAML — блок local_tts.
+type Config struct {
+	// Шаблон команды: {text} — текст аргументом (иначе в stdin), {out} — путь к WAV (иначе читаем stdout)
+	Command     string        `env:"LOCAL_TTS_COMMAND" yaml:"command"`
+	Timeout     time.Duration `env:"LOCAL_TTS_TIMEOUT" yaml:"timeout"`         // Предел одного синтеза (в пределах тика)
+	Concurrency int           `env:"LOCAL_TTS_CONCURRENCY" yaml:"concurrency"` // Сколько процессов движка одновременно
+}
+
+func init() {
+	tts.Register(tts.Spec[Config]{
+		Name: "local",
+		Caps: tts.Capabilities{Formats: []string{"wav"}},
+		Key:  "local_tts",
+		Defaults: Config{
+			Timeout:     30 * time.Second,
+			Concurrency: 1,
+		},
+		New: func(c Config, deps tts.Deps) tts.Synthesizer {
+			if _, err := CommandPath(c.Command); err != nil && deps.Logger != nil {
+				deps.Logger.Warnw("Local TTS engine not found", "command", c.Command, "error", err)
+			}
+			return New(c, deps.Logger)
+		},
+		Validate: func(c Config) []string {
+			var problems []string
+			if strings.TrimSpace(c.Command) == "" {
+				problems = append(problems, "LOCAL_TTS_COMMAND: required for TTS provider local (example: piper --model ru_RU-irina-medium.onnx --output_file {out})")
+			}
+			if c.Timeout < 0 {
+				problems = append(problems, fmt.Sprintf("LOCAL_TTS_TIMEOUT: must not be negative, got %s", c.Timeout))
+			}
+			if c.Concurrency < 1 {
+				problems = append(problems, fmt.Sprintf("LOCAL_TTS_CONCURRENCY: must be >= 1, got %d", c.Concurrency))
+			}
+			return problems
+		},
+	})
+}
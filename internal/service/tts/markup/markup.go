@@ -0,0 +1,265 @@
+package markup
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect — разметка, которую понимает провайдер TTS.
+type Dialect string
+
+const (
+	Plain  Dialect = ""       // без разметки: теги вырезаются
+	SSML   Dialect = "ssml"   // SSML (Google Cloud TTS, Gemini с GEMINI_TTS_INPUT_TYPE=ssml)
+	Yandex Dialect = "yandex" // TTS-разметка SpeechKit: sil<[ms]>, **ударение голосом**
+	Gemini Dialect = "gemini" // теги Gemini-TTS: [short pause], [whispering]
+)
+
+// Стили участков текста (парные теги)
+const (
+	styleEmphasis = "emphasis"
+	styleWhisper  = "whisper"
+	styleSlow     = "slow"
+	styleFast     = "fast"
+)
+
+const (
+	defaultPause = 500 * time.Millisecond
+	maxPause     = 5 * time.Second
+)
+
+// Hint — подсказка модели о нейтральной разметке (добавляется к промпту ассистента при TTS_MARKUP=true).
+const Hint = "Можно размечать речь тегами: [pause] или [pause 800ms] — пауза; " +
+	"[emphasis]слово[/emphasis] — выделение; [whisper]текст[/whisper] — шёпот; " +
+	"[slow]текст[/slow] и [fast]текст[/fast] — темп. Другие квадратные скобки не используй."
+
+// tagRe — тег нейтральной разметки: [pause], [pause 300ms], [emphasis], [/whisper] и т.п.
+var tagRe = regexp.MustCompile(`(?i)\[\s*(/?)\s*(pause|emphasis|em|whisper|slow|fast)(?:\s+([0-9.]+\s*(?:ms|s)?))?\s*\]`)
+
+// token — кусок разобранного текста: текст, пауза, открытие или закрытие стиля.
+type token struct {
+	text  string
+	pause time.Duration
+	open  string
+	close string
+}
+
+// parse разбирает текст в токены. Незакрытые стили закрываются в конце, лишние закрывающие теги отбрасываются,
+// а закрытие внешнего стиля закрывает и вложенные — результат всегда правильно вложен.
+// Квадратные скобки, не являющиеся тегами разметки, остаются текстом.
+func parse(s string) []token {
+	tokens, _ := parseOpen(s)
+	return tokens
+}
+
+// parseOpen — parse, дополнительно возвращающий стили, оставшиеся открытыми к концу s (внешний первым).
+func parseOpen(s string) ([]token, []string) {
+	var out []token
+	var stack []string
+	closeTo := func(style string) {
+		i := len(stack) - 1
+		for i >= 0 && stack[i] != style {
+			i--
+		}
+		if i < 0 {
+			return // закрытие без открытия
+		}
+		for j := len(stack) - 1; j >= i; j-- {
+			out = append(out, token{close: stack[j]})
+		}
+		stack = stack[:i]
+	}
+
+	last := 0
+	for _, m := range tagRe.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > last {
+			out = append(out, token{text: s[last:m[0]]})
+		}
+		last = m[1]
+		closing := m[3] > m[2]
+		name := strings.ToLower(s[m[4]:m[5]])
+		if name == "em" {
+			name = styleEmphasis
+		}
+		switch {
+		case name == "pause":
+			if !closing {
+				arg := ""
+				if m[6] >= 0 {
+					arg = s[m[6]:m[7]]
+				}
+				out = append(out, token{pause: parsePause(arg)})
+			}
+		case closing:
+			closeTo(name)
+		default:
+			if slices.Contains(stack, name) {
+				continue // повторное открытие того же стиля ничего не меняет
+			}
+			stack = append(stack, name)
+			out = append(out, token{open: name})
+		}
+	}
+	if last < len(s) {
+		out = append(out, token{text: s[last:]})
+	}
+	for j := len(stack) - 1; j >= 0; j-- {
+		out = append(out, token{close: stack[j]})
+	}
+	return out, stack
+}
+
+// Unclosed возвращает открывающие теги стилей, не закрытых к концу s, — чтобы продолжить их в следующем куске текста.
+func Unclosed(s string) string {
+	_, open := parseOpen(s)
+	var b strings.Builder
+	for _, style := range open {
+		b.WriteString("[" + style + "]")
+	}
+	return b.String()
+}
+
+// parsePause читает длительность паузы: "800ms", "1.5s", "700" (мс); пусто или ошибка — пауза по умолчанию.
+func parsePause(arg string) time.Duration {
+	arg = strings.ToLower(strings.ReplaceAll(arg, " ", ""))
+	if arg == "" {
+		return defaultPause
+	}
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		arg += "ms"
+	}
+	d, err := time.ParseDuration(arg)
+	if err != nil || d <= 0 {
+		return defaultPause
+	}
+	return min(d, maxPause)
+}
+
+// Render переводит нейтральную разметку в диалект провайдера; то, что провайдер не умеет, вырезается.
+func Render(s string, d Dialect) string {
+	switch d {
+	case SSML:
+		if strings.HasPrefix(strings.TrimSpace(s), "<speak") {
+			return s // уже готовый SSML (например, фраза из control API)
+		}
+		return renderSSML(parse(s))
+	case Yandex:
+		return renderYandex(parse(s))
+	case Gemini:
+		return renderGemini(parse(s))
+	default:
+		return Strip(s)
+	}
+}
+
+// MapText применяет fn к тексту между тегами (нормализация, словарь); сами теги и готовый SSML не меняются.
+func MapText(s string, fn func(string) string) string {
+	if strings.HasPrefix(strings.TrimSpace(s), "<speak") {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range tagRe.FindAllStringIndex(s, -1) {
+		b.WriteString(fn(s[last:m[0]]))
+		b.WriteString(s[m[0]:m[1]])
+		last = m[1]
+	}
+	b.WriteString(fn(s[last:]))
+	return b.String()
+}
+
+// Strip убирает теги разметки — для провайдеров без разметки, субтитров и логов.
+func Strip(s string) string {
+	var b strings.Builder
+	for _, t := range parse(s) {
+		switch {
+		case t.text != "":
+			b.WriteString(t.text)
+		case t.pause > 0:
+			b.WriteByte(' ')
+		}
+	}
+	return collapseSpaces(b.String())
+}
+
+func renderSSML(tokens []token) string {
+	var b strings.Builder
+	b.WriteString("<speak>")
+	for _, t := range tokens {
+		switch {
+		case t.text != "":
+			b.WriteString(html.EscapeString(t.text))
+		case t.pause > 0:
+			fmt.Fprintf(&b, `<break time="%dms"/>`, t.pause.Milliseconds())
+		case t.open == styleEmphasis:
+			b.WriteString(`<emphasis level="strong">`)
+		case t.close == styleEmphasis:
+			b.WriteString(`</emphasis>`)
+		case t.open == styleWhisper:
+			b.WriteString(`<prosody volume="x-soft" rate="slow">`)
+		case t.open == styleSlow:
+			b.WriteString(`<prosody rate="slow">`)
+		case t.open == styleFast:
+			b.WriteString(`<prosody rate="fast">`)
+		case t.close != "":
+			b.WriteString(`</prosody>`)
+		}
+	}
+	b.WriteString("</speak>")
+	return b.String()
+}
+
+// renderYandex: паузы — sil<[ms]>, выделение — **...**; шёпот и темп SpeechKit в тексте не поддерживает.
+func renderYandex(tokens []token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		switch {
+		case t.text != "":
+			b.WriteString(t.text)
+		case t.pause > 0:
+			fmt.Fprintf(&b, " sil<[%d]> ", t.pause.Milliseconds())
+		case t.open == styleEmphasis:
+			b.WriteString("**")
+		case t.close == styleEmphasis:
+			b.WriteString("**")
+		}
+	}
+	return collapseSpaces(b.String())
+}
+
+// renderGemini: паузы — [short|medium|long pause], шёпот — [whispering] перед участком; выделение и темп вырезаются.
+func renderGemini(tokens []token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		switch {
+		case t.text != "":
+			b.WriteString(t.text)
+		case t.pause > 0:
+			b.WriteString(" [" + geminiPause(t.pause) + "] ")
+		case t.open == styleWhisper:
+			b.WriteString(" [whispering] ")
+		}
+	}
+	return collapseSpaces(b.String())
+}
+
+func geminiPause(d time.Duration) string {
+	switch {
+	case d <= 300*time.Millisecond:
+		return "short pause"
+	case d <= time.Second:
+		return "medium pause"
+	default:
+		return "long pause"
+	}
+}
+
+// collapseSpaces схлопывает повторные пробелы, оставшиеся на месте вырезанных тегов.
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,135 @@
+package markup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []token
+	}{
+		{"pause default", "a [pause] b", []token{{text: "a "}, {pause: defaultPause}, {text: " b"}}},
+		{"pause ms", "[pause 800ms]", []token{{pause: 800 * time.Millisecond}}},
+		{"pause seconds", "[pause 1.5s]", []token{{pause: 1500 * time.Millisecond}}},
+		{"pause bare number", "[pause 700]", []token{{pause: 700 * time.Millisecond}}},
+		{"pause capped", "[pause 10s]", []token{{pause: maxPause}}},
+		{"case and spaces", "[ PAUSE ][ EM ]x[/ em ]", []token{{pause: defaultPause}, {open: styleEmphasis}, {text: "x"}, {close: styleEmphasis}}},
+		{
+			"nested",
+			"[whisper]x[emphasis]y[/emphasis][/whisper]z",
+			[]token{{open: styleWhisper}, {text: "x"}, {open: styleEmphasis}, {text: "y"}, {close: styleEmphasis}, {close: styleWhisper}, {text: "z"}},
+		},
+		{
+			"outer close closes inner",
+			"[slow]a[fast]b[/slow]c",
+			[]token{{open: styleSlow}, {text: "a"}, {open: styleFast}, {text: "b"}, {close: styleFast}, {close: styleSlow}, {text: "c"}},
+		},
+		{"unclosed closed at end", "[slow]x", []token{{open: styleSlow}, {text: "x"}, {close: styleSlow}}},
+		{"stray close dropped", "x[/fast]y", []token{{text: "x"}, {text: "y"}}},
+		{"reopen ignored", "[whisper][whisper]a[/whisper]", []token{{open: styleWhisper}, {text: "a"}, {close: styleWhisper}}},
+		{"other brackets are text", "[note] x", []token{{text: "[note] x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parse(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parse(%q)\n got %+v\nwant %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		in      string
+		want    string
+	}{
+		// SSML
+		{"ssml escaping", SSML, `a < b & "c"`, `<speak>a &lt; b &amp; &#34;c&#34;</speak>`},
+		{"ssml pause", SSML, "стоп[pause 300ms]дальше", `<speak>стоп<break time="300ms"/>дальше</speak>`},
+		{
+			"ssml nested", SSML, "[whisper]тихо [emphasis]очень[/emphasis][/whisper]",
+			`<speak><prosody volume="x-soft" rate="slow">тихо <emphasis level="strong">очень</emphasis></prosody></speak>`,
+		},
+		{
+			"ssml outer close", SSML, "[slow]a[fast]b[/slow]c",
+			`<speak><prosody rate="slow">a<prosody rate="fast">b</prosody></prosody>c</speak>`,
+		},
+		{"ssml unclosed", SSML, "[slow]медленно", `<speak><prosody rate="slow">медленно</prosody></speak>`},
+		{"ssml ready", SSML, " <speak>a < b</speak>", " <speak>a < b</speak>"},
+
+		// Yandex: шёпота и темпа нет — вырезаются
+		{"yandex emphasis and pause", Yandex, "Это [emphasis]важно[/emphasis] [pause 800ms] да", "Это **важно** sil<[800]> да"},
+		{"yandex unclosed emphasis", Yandex, "[emphasis]важно", "**важно**"},
+		{"yandex strips whisper and tempo", Yandex, "[whisper]тихо[/whisper] [fast]быстро[/fast] громко", "тихо быстро громко"},
+		{"yandex text as is", Yandex, "a < b & c", "a < b & c"},
+
+		// Gemini: выделения и темпа нет — вырезаются
+		{"gemini whisper", Gemini, "[whisper]секрет[/whisper] вслух", "[whispering] секрет вслух"},
+		{
+			"gemini pauses", Gemini, "a [pause 200ms] b [pause] c [pause 2s] d",
+			"a [short pause] b [medium pause] c [long pause] d",
+		},
+		{"gemini strips emphasis and tempo", Gemini, "[emphasis]да[/emphasis] [slow]нет[/slow]", "да нет"},
+		{"gemini unclosed whisper", Gemini, "[whisper]секрет", "[whispering] секрет"},
+
+		// Без разметки
+		{"plain", Plain, "Привет,[pause]мир [whisper]тихо", "Привет, мир тихо"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.in, tt.dialect); got != tt.want {
+				t.Fatalf("Render(%q, %q)\n got %q\nwant %q", tt.in, tt.dialect, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"[whisper]a[/whisper] [b]", "a [b]"},
+		{"один[pause]два", "один два"},
+		{"[slow] [fast] текст [/fast]", "текст"},
+		{"без тегов", "без тегов"},
+	}
+	for _, tt := range tests {
+		if got := Strip(tt.in); got != tt.want {
+			t.Errorf("Strip(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMapText(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"раз [pause] два [emphasis]три[/emphasis]", "РАЗ [pause] ДВА [emphasis]ТРИ[/emphasis]"},
+		{"[whisper 300ms]", "[whisper 300ms]"},
+		{"[note] x", "[NOTE] X"},
+		{"<speak>раз</speak>", "<speak>раз</speak>"},
+	}
+	for _, tt := range tests {
+		if got := MapText(tt.in, strings.ToUpper); got != tt.want {
+			t.Errorf("MapText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnclosed(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"[whisper]a[emphasis]b", "[whisper][emphasis]"},
+		{"[whisper]a[/whisper]", ""},
+		{"[slow]a[fast]b[/slow]", ""},
+		{"[em]a", "[emphasis]"},
+		{"[pause]", ""},
+	}
+	for _, tt := range tests {
+		if got := Unclosed(tt.in); got != tt.want {
+			t.Errorf("Unclosed(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package normalize
+
+import (
+	"OpenAIClient/internal/config"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// reloadCheck — как часто проверять, не изменился ли файл словаря.
+const reloadCheck = 2 * time.Second
+
+// dictFile — формат словаря произношения:
+//
+//	words:
+//	  ГК: главный калибр
+//	  Ямато: Ям+ато          # "+" перед ударной гласной
+//	providers:
+//	  yandex:
+//	    Ямато: Ямат+о
+type dictFile struct {
+	Words     map[string]string            `yaml:"words"`
+	Providers map[string]map[string]string `yaml:"providers"`
+}
+
+// rule — замена из словаря. Ключ без заглавных букв сравнивается без учёта регистра, с заглавными — точно (ТТ ≠ тт).
+type rule struct {
+	from, to string
+	fold     bool
+}
+
+type dictionary struct {
+	common    []rule
+	providers map[string][]rule
+}
+
+// Normalizer готовит текст ответа к синтезу: словарь произношения, числа и единицы словами, аббревиатуры по буквам.
+// Правки файла словаря подхватываются без перезапуска. nil-Normalizer текст не меняет.
+type Normalizer struct {
+	path   string
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	dict    *dictionary
+	modTime time.Time
+	checked time.Time
+}
+
+// New создаёт нормализатор; при выключенной нормализации возвращает nil.
+func New(cfg config.TTSNormalizeConfig, logger *zap.SugaredLogger) *Normalizer {
+	if !cfg.Enabled {
+		return nil
+	}
+	n := &Normalizer{path: strings.TrimSpace(cfg.Dictionary), logger: logger, dict: &dictionary{}}
+	n.reload()
+	return n
+}
+
+// Apply нормализует текст для провайдера provider. plusStress — провайдер понимает ударение "+" перед гласной (Yandex);
+// иначе ударение из словаря ставится знаком U+0301 после гласной.
+func (n *Normalizer) Apply(text, provider string, plusStress bool) string {
+	if n == nil || strings.TrimSpace(text) == "" {
+		return text
+	}
+	text = n.dictionary().replace(text, provider, plusStress)
+	text = numbers(text)
+	return abbreviations(text)
+}
+
+// dictionary возвращает актуальный словарь, перечитывая файл, если он изменился.
+func (n *Normalizer) dictionary() *dictionary {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if time.Since(n.checked) >= reloadCheck {
+		n.reloadLocked()
+	}
+	return n.dict
+}
+
+func (n *Normalizer) reload() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.reloadLocked()
+}
+
+// reloadLocked перечитывает словарь при смене mtime; с ошибкой разбора остаётся прежний словарь (под mu).
+func (n *Normalizer) reloadLocked() {
+	n.checked = time.Now()
+	if n.path == "" {
+		return
+	}
+	info, err := os.Stat(n.path)
+	if err != nil {
+		if !n.modTime.IsZero() {
+			n.logger.Infow("Pronunciation dictionary removed", "path", n.path)
+			n.dict, n.modTime = &dictionary{}, time.Time{}
+		}
+		return
+	}
+	if info.ModTime().Equal(n.modTime) {
+		return
+	}
+	n.modTime = info.ModTime()
+	d, err := load(n.path)
+	if err != nil {
+		n.logger.Warnw("Pronunciation dictionary not loaded", "path", n.path, "error", err)
+		return
+	}
+	n.dict = d
+	n.logger.Infow("Pronunciation dictionary loaded", "path", n.path, "words", len(d.common), "providers", len(d.providers))
+}
+
+// load читает и разбирает файл словаря.
+func load(path string) (*dictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f dictFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	d := &dictionary{common: rules(f.Words), providers: map[string][]rule{}}
+	for name, words := range f.Providers {
+		d.providers[config.NormalizeTTSProvider(name)] = rules(words)
+	}
+	return d, nil
+}
+
+// Validate проверяет файл словаря для config check: отсутствующий файл — не ошибка.
+func Validate(cfg config.TTSNormalizeConfig) error {
+	path := strings.TrimSpace(cfg.Dictionary)
+	if !cfg.Enabled || path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if _, err := load(path); err != nil {
+		return &config.ValidationError{Problems: []string{fmt.Sprintf("TTS_DICTIONARY: %s: %v", path, err)}}
+	}
+	return nil
+}
+
+// rules превращает пары словаря в правила: длинные ключи раньше коротких («Ямато Кай» раньше «Ямато»).
+func rules(words map[string]string) []rule {
+	out := make([]rule, 0, len(words))
+	for from, to := range words {
+		if from = strings.TrimSpace(from); from == "" {
+			continue
+		}
+		out = append(out, rule{from: from, to: strings.TrimSpace(to), fold: strings.ToLower(from) == from})
+	}
+	slices.SortFunc(out, func(a, b rule) int {
+		if d := len(b.from) - len(a.from); d != 0 {
+			return d
+		}
+		return strings.Compare(a.from, b.from)
+	})
+	return out
+}
+
+// replace заменяет слова словаря (целыми словами): сначала правила провайдера, затем общие.
+func (d *dictionary) replace(s, provider string, plusStress bool) string {
+	lists := [][]rule{d.providers[provider], d.common}
+	if len(lists[0]) == 0 && len(lists[1]) == 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if boundaryBefore(s, i) {
+			if r, ok := match(lists, s[i:]); ok {
+				b.WriteString(stress(r.to, plusStress))
+				i += len(r.from)
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	return b.String()
+}
+
+// match ищет правило, ключ которого стоит в начале rest и кончается на границе слова.
+func match(lists [][]rule, rest string) (rule, bool) {
+	for _, list := range lists {
+		for _, r := range list {
+			if len(rest) < len(r.from) {
+				continue
+			}
+			head := rest[:len(r.from)]
+			if (r.fold && !strings.EqualFold(head, r.from)) || (!r.fold && head != r.from) {
+				continue
+			}
+			if boundaryAfter(rest, len(r.from)) {
+				return r, true
+			}
+		}
+	}
+	return rule{}, false
+}
+
+// boundaryBefore — слово может начинаться в позиции i: слева нет буквы или цифры.
+func boundaryBefore(s string, i int) bool {
+	prev, _ := utf8.DecodeLastRuneInString(s[:i])
+	return i == 0 || !isWordRune(prev)
+}
+
+// boundaryAfter — слово может кончаться в позиции i: справа нет буквы или цифры.
+func boundaryAfter(s string, i int) bool {
+	next, _ := utf8.DecodeRuneInString(s[i:])
+	return i == len(s) || !isWordRune(next)
+}
+
+func isWordRune(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+const vowels = "аеёиоуыэюяАЕЁИОУЫЭЮЯaeiouyAEIOUY"
+
+// stress переводит ударение "+гласная" в знак U+0301 после гласной для провайдеров без поддержки "+".
+func stress(s string, plus bool) string {
+	if plus || !strings.Contains(s, "+") {
+		return s
+	}
+	var b strings.Builder
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '+' && i+1 < len(rs) && strings.ContainsRune(vowels, rs[i+1]) {
+			b.WriteRune(rs[i+1])
+			b.WriteRune('\u0301')
+			i++
+			continue
+		}
+		b.WriteRune(rs[i])
+	}
+	return b.String()
+}
+
+// letters — названия букв для чтения аббревиатур.
+var letters = map[rune]string{
+	'А': "а", 'Б': "бэ", 'В': "вэ", 'Г': "гэ", 'Д': "дэ", 'Е': "е", 'Ё': "ё", 'Ж': "жэ", 'З': "зэ", 'И': "и",
+	'Й': "й", 'К': "ка", 'Л': "эль", 'М': "эм", 'Н': "эн", 'О': "о", 'П': "пэ", 'Р': "эр", 'С': "эс", 'Т': "тэ",
+	'У': "у", 'Ф': "эф", 'Х': "ха", 'Ц': "цэ", 'Ч': "че", 'Ш': "ша", 'Щ': "ща", 'Ы': "ы", 'Э': "э", 'Ю': "ю", 'Я': "я",
+	'A': "эй", 'B': "би", 'C': "си", 'D': "ди", 'E': "и", 'F': "эф", 'G': "джи", 'H': "эйч", 'I': "ай", 'J': "джей",
+	'K': "кей", 'L': "эл", 'M': "эм", 'N': "эн", 'O': "оу", 'P': "пи", 'Q': "кью", 'R': "ар", 'S': "эс", 'T': "ти",
+	'U': "ю", 'V': "ви", 'W': "дабл-ю", 'X': "экс", 'Y': "уай", 'Z': "зед",
+}
+
+// abbreviations читает по буквам слова из заглавных (2–5 букв) без гласных: ГК, ПМК, ТТ, HP, MVP.
+// Слова с гласными (НАТО, МИД, ДА, GO) и римские числа (XX) остаются как есть; особые случаи (ИИ, ЕС) — в словаре.
+func abbreviations(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !unicode.IsUpper(r) || !boundaryBefore(s, i) {
+			b.WriteString(s[i : i+size])
+			i += size
+			continue
+		}
+		j := i
+		var word []rune
+		for j < len(s) {
+			r, size := utf8.DecodeRuneInString(s[j:])
+			if !isWordRune(r) {
+				break
+			}
+			word = append(word, r)
+			j += size
+		}
+		b.WriteString(spellOut(word, s[i:j]))
+		i = j
+	}
+	return b.String()
+}
+
+// spellOut возвращает аббревиатуру по буквам или исходное слово, если это не аббревиатура.
+func spellOut(word []rune, orig string) string {
+	if len(word) < 2 || len(word) > 5 {
+		return orig
+	}
+	names := make([]string, 0, len(word))
+	for _, r := range word {
+		name, ok := letters[r]
+		if !ok || strings.ContainsRune(vowels, r) {
+			return orig // гласная, строчные буквы, цифры, смесь алфавитов
+		}
+		names = append(names, name)
+	}
+	if strings.Trim(orig, "IVXLCDM") == "" {
+		return orig // римское число
+	}
+	return strings.Join(names, "-")
+}
@@ -0,0 +1,275 @@
+package normalize
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// forms — формы слова для 1, 2–4 и 5+ (одна минута, две минуты, пять минут).
+type forms [3]string
+
+// plural выбирает форму по последним цифрам n.
+func (f forms) plural(n int64) string {
+	switch n %= 100; {
+	case n >= 11 && n <= 14:
+		return f[2]
+	case n%10 == 1:
+		return f[0]
+	case n%10 >= 2 && n%10 <= 4:
+		return f[1]
+	default:
+		return f[2]
+	}
+}
+
+// unit — единица после числа: формы и род (числительное согласуется: одна минута, два метра).
+type unit struct {
+	forms
+	fem bool
+}
+
+// units — единицы измерения и сокращения после чисел; ключи в нижнем регистре.
+var units = map[string]unit{
+	"%":    {forms{"процент", "процента", "процентов"}, false},
+	"км/ч": {forms{"километр в час", "километра в час", "километров в час"}, false},
+	"км":   {forms{"километр", "километра", "километров"}, false},
+	"м":    {forms{"метр", "метра", "метров"}, false},
+	"см":   {forms{"сантиметр", "сантиметра", "сантиметров"}, false},
+	"мм":   {forms{"миллиметр", "миллиметра", "миллиметров"}, false},
+	"кг":   {forms{"килограмм", "килограмма", "килограммов"}, false},
+	"мс":   {forms{"миллисекунда", "миллисекунды", "миллисекунд"}, true},
+	"сек":  {forms{"секунда", "секунды", "секунд"}, true},
+	"мин":  {forms{"минута", "минуты", "минут"}, true},
+	"ч":    {forms{"час", "часа", "часов"}, false},
+	"уз":   {forms{"узел", "узла", "узлов"}, false},
+	"°":    {forms{"градус", "градуса", "градусов"}, false},
+	"руб":  {forms{"рубль", "рубля", "рублей"}, false},
+	"₽":    {forms{"рубль", "рубля", "рублей"}, false},
+	"$":    {forms{"доллар", "доллара", "долларов"}, false},
+	"к":    {forms{"тысяча", "тысячи", "тысяч"}, true},
+	"k":    {forms{"тысяча", "тысячи", "тысяч"}, true},
+	"тыс":  {forms{"тысяча", "тысячи", "тысяч"}, true},
+	"млн":  {forms{"миллион", "миллиона", "миллионов"}, false},
+	"млрд": {forms{"миллиард", "миллиарда", "миллиардов"}, false},
+}
+
+// unitKeys — ключи units от длинных к коротким: "км/ч" раньше "км", "мс" раньше "м".
+var unitKeys = func() []string {
+	keys := make([]string, 0, len(units))
+	for k := range units {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int { return utf8.RuneCountInString(b) - utf8.RuneCountInString(a) })
+	return keys
+}()
+
+var (
+	onesMasc = []string{"ноль", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	onesFem  = []string{"ноль", "одна", "две", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	teens    = []string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать", "шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	tens     = []string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	hundreds = []string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+
+	// scales — разряды от старшего: род и формы
+	scales = []struct {
+		value int64
+		unit
+	}{
+		{1_000_000_000, unit{forms{"миллиард", "миллиарда", "миллиардов"}, false}},
+		{1_000_000, unit{forms{"миллион", "миллиона", "миллионов"}, false}},
+		{1_000, unit{forms{"тысяча", "тысячи", "тысяч"}, true}},
+	}
+
+	fractions = []forms{
+		{"десятая", "десятых", "десятых"},
+		{"сотая", "сотых", "сотых"},
+		{"тысячная", "тысячных", "тысячных"},
+	}
+	wholes = forms{"целая", "целых", "целых"}
+)
+
+// cardinal — количественное числительное словами (0 ≤ n < 10^12); fem — женский род (одна, две).
+func cardinal(n int64, fem bool) string {
+	if n == 0 {
+		return onesMasc[0]
+	}
+	var words []string
+	for _, s := range scales {
+		if k := n / s.value; k > 0 {
+			words = append(words, triple(k, s.fem)...)
+			words = append(words, s.plural(k))
+			n %= s.value
+		}
+	}
+	words = append(words, triple(n, fem)...)
+	return strings.Join(words, " ")
+}
+
+// triple — слова для 1..999 (0 — пусто).
+func triple(n int64, fem bool) []string {
+	var words []string
+	if h := n / 100; h > 0 {
+		words = append(words, hundreds[h])
+	}
+	switch t := n % 100; {
+	case t >= 10 && t < 20:
+		words = append(words, teens[t-10])
+	default:
+		if t/10 > 0 {
+			words = append(words, tens[t/10])
+		}
+		if t%10 > 0 {
+			if fem {
+				words = append(words, onesFem[t%10])
+			} else {
+				words = append(words, onesMasc[t%10])
+			}
+		}
+	}
+	return words
+}
+
+// numberRe — целое (в том числе с разрядами через пробел: 10 000) с необязательной дробной частью.
+var numberRe = regexp.MustCompile(`\d{1,3}(?:[ \x{00A0}]\d{3})+(?:[.,]\d+)?|\d+(?:[.,]\d+)?`)
+
+// numbers заменяет числа словами; единица сразу после числа (5 км, 30%, 2,5 мин) согласуется с ним.
+func numbers(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range numberRe.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		if start < last {
+			continue
+		}
+		// Число внутри слова или номера (T34, 1.2.3, 12-й) не трогаем
+		if !standalone(s, start, end) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		// Минус: "-5" в начале или после пробела
+		prefix := ""
+		if start > 0 && s[start-1] == '-' && (start == 1 || s[start-2] == ' ') {
+			trimmed := strings.TrimSuffix(b.String(), "-")
+			b.Reset()
+			b.WriteString(trimmed)
+			prefix = "минус "
+		}
+		u, uEnd := unitAt(s, end)
+		// Символ валюты перед числом ($5)
+		if u == nil && start > 0 && s[start-1] == '$' {
+			trimmed := strings.TrimSuffix(b.String(), "$")
+			b.Reset()
+			b.WriteString(trimmed)
+			cur := units["$"]
+			u = &cur
+		}
+		b.WriteString(prefix + spell(s[start:end], u))
+		last = max(end, uEnd)
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// spell читает число (с дробной частью) и единицу после него.
+func spell(num string, u *unit) string {
+	num = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' {
+			return -1
+		}
+		return r
+	}, num)
+	intPart, frac, hasFrac := strings.Cut(strings.ReplaceAll(num, ",", "."), ".")
+	n, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || n >= 1_000_000_000_000 {
+		return digits(num) // слишком длинное — по цифрам
+	}
+	fem := u != nil && u.fem
+
+	if !hasFrac {
+		out := cardinal(n, fem)
+		if u != nil {
+			out += " " + u.plural(n)
+		}
+		return out
+	}
+	f, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil || len(frac) > len(fractions) {
+		out := cardinal(n, fem) + " запятая " + digits(frac)
+		if u != nil {
+			out += " " + u.forms[1]
+		}
+		return out
+	}
+	// 2,5 км — «две целых пять десятых километра»: дробь в женском роде, единица в родительном падеже
+	out := cardinal(n, true) + " " + wholes.plural(n) + " " + cardinal(f, true) + " " + fractions[len(frac)-1].plural(f)
+	if u != nil {
+		out += " " + u.forms[1]
+	}
+	return out
+}
+
+// digits читает строку цифр по одной.
+func digits(s string) string {
+	var words []string
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			words = append(words, onesMasc[r-'0'])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// unitAt ищет единицу сразу после числа (через необязательный пробел) и возвращает её и позицию конца.
+func unitAt(s string, pos int) (*unit, int) {
+	rest := s[pos:]
+	skip := 0
+	if strings.HasPrefix(rest, " ") {
+		skip = 1
+	}
+	rest = rest[skip:]
+	for _, k := range unitKeys {
+		if len(rest) < len(k) || !strings.EqualFold(rest[:len(k)], k) {
+			continue
+		}
+		// Буквенная единица должна кончаться на границе слова: «5 минут» — не «мин»
+		after := rest[len(k):]
+		if r, _ := utf8.DecodeRuneInString(after); after != "" && unicode.IsLetter(r) {
+			continue
+		}
+		// Символы (%, °) и «к» (10к) пишутся слитно; через пробел их не ищем: «5 к врагу» — не тысячи
+		if skip == 1 && (!unicode.IsLetter([]rune(k)[0]) || k == "к" || k == "k") {
+			continue
+		}
+		u := units[k]
+		return &u, pos + skip + len(k)
+	}
+	return nil, pos
+}
+
+// standalone — число s[start:end] стоит отдельно: не приклеено к буквам слева, не часть номера версии,
+// модели (T-34, Су-27) и не «12-й».
+func standalone(s string, start, end int) bool {
+	prev, size := utf8.DecodeLastRuneInString(s[:start])
+	if start > 0 && (unicode.IsLetter(prev) || prev == '.' || prev == ',') {
+		return false
+	}
+	if before, _ := utf8.DecodeLastRuneInString(s[:start-size]); prev == '-' && unicode.IsLetter(before) {
+		return false
+	}
+	if end == len(s) {
+		return true
+	}
+	next, size := utf8.DecodeRuneInString(s[end:])
+	after, _ := utf8.DecodeRuneInString(s[end+size:])
+	switch next {
+	case '.', ',':
+		return !unicode.IsDigit(after)
+	case '-':
+		return !unicode.IsLetter(after)
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package normalize
+
+import "testing"
+
+func TestCardinal(t *testing.T) {
+	tests := []struct {
+		n    int64
+		fem  bool
+		want string
+	}{
+		{0, false, "ноль"},
+		{1, false, "один"},
+		{1, true, "одна"},
+		{2, true, "две"},
+		{11, false, "одиннадцать"},
+		{12, true, "двенадцать"},
+		{13, false, "тринадцать"},
+		{14, false, "четырнадцать"},
+		{21, true, "двадцать одна"},
+		{112, false, "сто двенадцать"},
+		{1000, false, "одна тысяча"},
+		{2000, false, "две тысячи"},
+		{5000, false, "пять тысяч"},
+		{11000, false, "одиннадцать тысяч"},
+		{14000, false, "четырнадцать тысяч"},
+		{21000, false, "двадцать одна тысяча"},
+		{22002, false, "двадцать две тысячи два"},
+		{1_000_000, false, "один миллион"},
+		{2_000_000_001, false, "два миллиарда один"},
+	}
+	for _, tt := range tests {
+		if got := cardinal(tt.n, tt.fem); got != tt.want {
+			t.Errorf("cardinal(%d, fem=%v) = %q, want %q", tt.n, tt.fem, got, tt.want)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"12 км", "двенадцать километров"},
+		{"1 мин", "одна минута"},
+		{"2 мин", "две минуты"},
+		{"11 мин", "одиннадцать минут"},
+		{"35%", "тридцать пять процентов"},
+		{"2,5 км", "две целых пять десятых километра"},
+		{"1.25 ч", "одна целая двадцать пять сотых часа"},
+		{"-5 градусов", "минус пять градусов"},
+		{"было -5", "было минус пять"},
+		{"$5", "пять долларов"},
+		{"5$", "пять долларов"},
+		{"10к", "десять тысяч"},
+		{"1к золота", "одна тысяча золота"},
+		{"5 к врагу", "пять к врагу"},
+		{"10 000", "десять тысяч"},
+		{"1 000 000 руб", "один миллион рублей"},
+		{"T34", "T34"},
+		{"танк T-34", "танк T-34"},
+		{"версия 1.2.3", "версия 1.2.3"},
+		{"12-й уровень", "12-й уровень"},
+		{"в 5-10 минут", "в пять-десять минут"},
+		{"Конец фразы 3.", "Конец фразы три."},
+		{"1, 2, 3", "один, два, три"},
+	}
+	for _, tt := range tests {
+		if got := numbers(tt.in); got != tt.want {
+			t.Errorf("numbers(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAbbreviations(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ГК", "гэ-ка"},
+		{"ПМК готов", "пэ-эм-ка готов"},
+		{"ТТ", "тэ-тэ"},
+		{"HP", "эйч-пи"},
+		{"MVP", "эм-ви-пи"},
+		{"ДА", "ДА"},
+		{"НЕ", "НЕ"},
+		{"GO", "GO"},
+		{"НАТО", "НАТО"},
+		{"II", "II"},
+		{"IV", "IV"},
+		{"XX век", "XX век"},
+		{"Гк", "Гк"},
+		{"ГКХЗЛМ", "ГКХЗЛМ"},
+	}
+	for _, tt := range tests {
+		if got := abbreviations(tt.in); got != tt.want {
+			t.Errorf("abbreviations(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package openai
+
+import (
+	"OpenAIClient/internal/service/metrics"
+	"OpenAIClient/internal/service/tts"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	oai "github.com/openai/openai-go/v3"
+	"go.uber.org/zap"
+)
+
+// Client реализует синтез речи через OpenAI Audio API (POST /audio/speech) общим клиентом OpenAI.
+type Client struct {
+	client *oai.Client
+	cfg    Config
+	logger *zap.SugaredLogger
+}
+
+// New создаёт провайдера поверх клиента OpenAI (ключ и адрес API — как у запросов к ИИ).
+func New(client *oai.Client, cfg Config, logger *zap.SugaredLogger) *Client {
+	return &Client{client: client, cfg: cfg, logger: logger}
+}
+
+// Synthesize выполняет запрос к OpenAI TTS и возвращает аудио.
+// prompt — инструкции голоса (текст персонажа), как промпт у Gemini; пустой — берём OPENAI_TTS_INSTRUCTIONS.
+func (c *Client) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	start := time.Now()
+	format, rc, err := c.synthesize(ctx, text, prompt)
+	metrics.ObserveTTS("openai", time.Since(start), err)
+	return format, rc, err
+}
+
+// synthesize — сам запрос к провайдеру; Synthesize оборачивает его метриками.
+func (c *Client) synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	oc := c.cfg
+	if strings.TrimSpace(text) == "" {
+		return "", nil, errors.New("openai tts: empty input text")
+	}
+
+	format := strings.ToLower(strings.TrimSpace(oc.Format))
+	params := oai.AudioSpeechNewParams{
+		Input:          text,
+		Model:          strings.TrimSpace(oc.Model),
+		Voice:          oai.AudioSpeechNewParamsVoice(strings.TrimSpace(oc.Voice)),
+		ResponseFormat: oai.AudioSpeechNewParamsResponseFormat(format),
+	}
+	// Инструкции не поддерживаются моделями tts-1/tts-1-hd — API их отклоняет, не отправляем
+	instructions := strings.TrimSpace(prompt)
+	if instructions == "" {
+		instructions = strings.TrimSpace(oc.Instructions)
+	}
+	if instructions != "" && !strings.HasPrefix(params.Model, "tts-1") {
+		params.Instructions = oai.String(instructions)
+	}
+	if oc.Speed > 0 {
+		params.Speed = oai.Float(oc.Speed)
+	}
+
+	resp, err := c.client.Audio.Speech.New(ctx, params)
+	if err != nil {
+		// SDK сам превращает не-2xx ответ в ошибку
+		var apiErr *oai.Error
+		if errors.As(err, &apiErr) {
+			return "", nil, tts.StatusError(apiErr.StatusCode, fmt.Errorf("openai tts: %w", err))
+		}
+		return "", nil, fmt.Errorf("openai tts: %w", err)
+	}
+	if c.logger != nil {
+		c.logger.Debugw("OpenAI TTS response", "model", params.Model, "voice", params.Voice, "format", format)
+	}
+	return format, resp.Body, nil
+}
@@ -0,0 +1,80 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	oai "github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+func newStubClient(t *testing.T, cfg Config, h http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	client := oai.NewClient(option.WithBaseURL(srv.URL), option.WithAPIKey("test"), option.WithMaxRetries(0))
+	return New(&client, cfg, nil)
+}
+
+func TestSynthesize_SendsSettingsAndInstructions(t *testing.T) {
+	var got map[string]any
+	cfg := Config{Model: "gpt-4o-mini-tts", Voice: "coral", Instructions: "default", Speed: 1.2, Format: "mp3"}
+	c := newStubClient(t, cfg, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/speech" {
+			t.Errorf("path = %s, want /audio/speech", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("ID3-audio"))
+	})
+
+	format, rc, err := c.Synthesize(context.Background(), "Привет", "Говори как пират")
+	if err != nil {
+		t.Fatalf("synthesize: %v", err)
+	}
+	defer rc.Close()
+	audio, _ := io.ReadAll(rc)
+
+	if format != "mp3" || string(audio) != "ID3-audio" {
+		t.Fatalf("got format=%q audio=%q", format, audio)
+	}
+	want := map[string]any{"input": "Привет", "model": "gpt-4o-mini-tts", "voice": "coral", "instructions": "Говори как пират", "speed": 1.2, "response_format": "mp3"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("request %s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestSynthesize_NoInstructionsForTTS1(t *testing.T) {
+	var got map[string]any
+	c := newStubClient(t, Config{Model: "tts-1", Voice: "alloy", Format: "wav"}, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte("RIFF"))
+	})
+
+	_, rc, err := c.Synthesize(context.Background(), "Привет", "Говори как пират")
+	if err != nil {
+		t.Fatalf("synthesize: %v", err)
+	}
+	rc.Close()
+	if _, ok := got["instructions"]; ok {
+		t.Fatalf("instructions sent to tts-1: %v", got)
+	}
+}
+
+func TestSynthesize_ErrorStatus(t *testing.T) {
+	c := newStubClient(t, Config{Model: "tts-1", Voice: "nope", Format: "mp3"}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"bad voice"}}`, http.StatusBadRequest)
+	})
+
+	if _, _, err := c.Synthesize(context.Background(), "Привет", ""); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+}
@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"OpenAIClient/internal/service/tts"
+	"fmt"
+	"strings"
+)
+
+// Config — синтез речи через OpenAI Audio API; ключ — общий OPENAI_API_KEY. В YAML — блок openai_tts.
+type Config struct {
+	Model        string  `env:"OPENAI_TTS_MODEL" yaml:"model"`               // gpt-4o-mini-tts|tts-1|tts-1-hd
+	Voice        string  `env:"OPENAI_TTS_VOICE" yaml:"voice"`               // alloy, coral, sage, shimmer, marin...
+	Instructions string  `env:"OPENAI_TTS_INSTRUCTIONS" yaml:"instructions"` // Инструкции голоса, если у персонажа нет текста
+	Speed        float64 `env:"OPENAI_TTS_SPEED" yaml:"speed"`               // 0.25–4.0; 0 — по умолчанию API
+	Format       string  `env:"OPENAI_TTS_FORMAT" yaml:"format"`             // mp3|wav|opus|flac
+}
+
+func init() {
+	tts.Register(tts.Spec[Config]{
+		Name: "openai",
+		Caps: tts.Capabilities{Formats: []string{"mp3", "wav", "opus", "flac"}, StylePrompt: true, MaxTextLen: 4096},
+		Key:  "openai_tts",
+		Defaults: Config{
+			Model:  "gpt-4o-mini-tts",
+			Voice:  "coral",
+			Speed:  1.0,
+			Format: "mp3",
+		},
+		New: func(c Config, deps tts.Deps) tts.Synthesizer {
+			return New(deps.OpenAI, c, deps.Logger)
+		},
+		Validate: func(c Config) []string {
+			var problems []string
+			switch strings.ToLower(strings.TrimSpace(c.Format)) {
+			case "mp3", "wav", "opus", "flac":
+			default:
+				problems = append(problems, fmt.Sprintf("OPENAI_TTS_FORMAT: unsupported value %q (mp3|wav|opus|flac)", c.Format))
+			}
+			if s := c.Speed; s != 0 && (s < 0.25 || s > 4) {
+				problems = append(problems, fmt.Sprintf("OPENAI_TTS_SPEED: must be within 0.25–4.0, got %g", s))
+			}
+			return problems
+		},
+	})
+}
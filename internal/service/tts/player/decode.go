@@ -0,0 +1,58 @@
+package player
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/flac"
+	"github.com/faiface/beep/mp3"
+	"github.com/faiface/beep/vorbis"
+	"github.com/faiface/beep/wav"
+)
+
+// decode выбирает декодер по формату клипа: формату провайдера (oggopus) или расширению файла (.ogg, .flac).
+func decode(format string, r io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	switch strings.ToLower(format) {
+	case "wav":
+		return wav.Decode(r)
+	case "mp3":
+		return mp3.Decode(r)
+	case "flac":
+		return flac.Decode(r)
+	case "ogg", "oga", "opus", "oggopus", "ogg_opus", "vorbis", "oggvorbis", "ogg_vorbis":
+		return decodeOgg(r)
+	default:
+		_ = r.Close()
+		return nil, beep.Format{}, errors.New("unsupported format for direct playback; use mp3, wav, ogg (opus, vorbis) or flac")
+	}
+}
+
+// decodeOgg определяет кодек Ogg по первому пакету: у одного и того же .ogg внутри может быть Opus или Vorbis.
+func decodeOgg(r io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(64) // заголовок первой страницы и начало первого пакета
+	rc := readCloser{Reader: br, Closer: r}
+	switch {
+	case bytes.Contains(head, []byte("OpusHead")):
+		return decodeOpus(rc)
+	case bytes.Contains(head, []byte("\x01vorbis")):
+		s, f, err := vorbis.Decode(rc)
+		if err != nil {
+			_ = r.Close()
+		}
+		return s, f, err
+	default:
+		_ = r.Close()
+		return nil, beep.Format{}, errors.New("ogg: unknown codec (expected opus or vorbis)")
+	}
+}
+
+// readCloser читает из буфера, а закрывает исходный поток.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,18 @@
+//go:build !nospeaker
+
+package player
+
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+// Звуковая карта — beep/speaker. Его мьютекс — общий замок микшера: устройство вызывает Stream под ним же.
+
+func deviceInit(rate beep.SampleRate, bufferSize int) error { return speaker.Init(rate, bufferSize) }
+
+func devicePlay(s beep.Streamer) { speaker.Play(s) }
+
+func lock() { speaker.Lock() }
+
+func unlock() { speaker.Unlock() }
@@ -0,0 +1,25 @@
+//go:build nospeaker
+
+package player
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/faiface/beep"
+)
+
+// Сборка без звуковой карты (go test -tags nospeaker): на системах без ALSA плеер работает с выходами files,
+// session и discard, которые тактирует таймер; выход speaker возвращает ошибку при первом Play.
+
+var mu sync.Mutex
+
+func deviceInit(beep.SampleRate, int) error {
+	return errors.New("player: built with nospeaker tag, speaker sink is unavailable")
+}
+
+func devicePlay(beep.Streamer) {}
+
+func lock() { mu.Lock() }
+
+func unlock() { mu.Unlock() }
@@ -0,0 +1,100 @@
+package player
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// envelopeWindow — шаг огибающей речи для липсинка аватара.
+const envelopeWindow = 10 * time.Millisecond
+
+// envFrame — кадр огибающей: средний квадрат сигнала и его первой разности (доля высоких частот).
+type envFrame struct {
+	due      time.Time // когда кадр прозвучит (с учётом буфера устройства)
+	ms, diff float64
+}
+
+// envelope — огибающая звучащей речи. Кадры считаются в аудиопотоке заранее, на длину буфера устройства,
+// и отдаются SpeechEnvelope, когда наступает их время, — рот двигается вместе со звуком, а не раньше.
+type envelope struct {
+	latency time.Duration
+
+	mu     sync.Mutex
+	frames []envFrame
+}
+
+func (e *envelope) push(frames []envFrame) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// Никто не забирает кадры (липсинк выключен) — прозвучавшие больше секунды назад выбрасываем
+	old := 0
+	for old < len(e.frames) && time.Since(e.frames[old].due) > time.Second {
+		old++
+	}
+	e.frames = e.frames[:copy(e.frames, e.frames[old:])]
+	e.frames = append(e.frames, frames...)
+}
+
+// meter копит кадры огибающей одного клипа речи; вызывается только из аудиопотока.
+type meter struct {
+	env    *envelope
+	rate   beep.SampleRate
+	window int
+
+	n               int
+	sum, diff, prev float64
+}
+
+func newMeter(env *envelope, rate beep.SampleRate) *meter {
+	return &meter{env: env, rate: rate, window: max(1, rate.N(envelopeWindow))}
+}
+
+// add учитывает сэмплы, только что отданные устройству (уже с усилением, приглушением и затуханием клипа).
+func (mt *meter) add(samples [][2]float64) {
+	start := time.Now().Add(mt.env.latency)
+	var out []envFrame
+	for i, s := range samples {
+		x := (s[0] + s[1]) / 2
+		d := x - mt.prev
+		mt.prev = x
+		mt.sum += x * x
+		mt.diff += d * d
+		if mt.n++; mt.n == mt.window {
+			out = append(out, envFrame{due: start.Add(mt.rate.D(i)), ms: mt.sum / float64(mt.n), diff: mt.diff / float64(mt.n)})
+			mt.n, mt.sum, mt.diff = 0, 0, 0
+		}
+	}
+	if len(out) > 0 {
+		mt.env.push(out)
+	}
+}
+
+// SpeechEnvelope возвращает огибающую речи, звучащей сейчас: уровень в dBFS (-Inf — тишина) и яркость 0..1 —
+// долю высоких частот («и», «с» ярче «о», «у»). speaking — речь играет (в том числе паузы внутри неё).
+// Вызывается липсинком с частотой кадров аватара; из кадров, прозвучавших с прошлого вызова, берётся самый громкий.
+func (m *Mixer) SpeechEnvelope() (levelDB, brightness float64, speaking bool) {
+	e := m.env
+	now := time.Now()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var loud envFrame
+	i := 0
+	for ; i < len(e.frames) && !e.frames[i].due.After(now); i++ {
+		if e.frames[i].ms >= loud.ms {
+			loud = e.frames[i]
+		}
+	}
+	e.frames = e.frames[:copy(e.frames, e.frames[i:])]
+	if i == 0 && len(e.frames) == 0 {
+		return math.Inf(-1), 0, false
+	}
+	levelDB = 10 * math.Log10(loud.ms)
+	if loud.ms > 0 {
+		// Для белого шума разность вдвое мощнее сигнала, для гласных — в разы слабее
+		brightness = min(1, loud.diff/(2*loud.ms))
+	}
+	return levelDB, brightness, true
+}
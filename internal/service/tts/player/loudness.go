@@ -0,0 +1,296 @@
+package player
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Loudness — выравнивание громкости клипов: речь разных провайдеров и уведомления звучат одинаково громко.
+type Loudness struct {
+	Enabled    bool
+	TargetLUFS float64 // целевая интегральная громкость (ITU-R BS.1770), LUFS
+	MaxGainDB  float64 // предел усиления тихих клипов
+	CeilingDB  float64 // потолок пиков после усиления, dBFS
+}
+
+const (
+	// measureWindow — сколько начала клипа декодируется до старта, чтобы сразу выбрать усиление;
+	// дальше громкость уточняется по ходу проигрывания, и клип не ждёт полной загрузки
+	measureWindow = 2500 * time.Millisecond
+	// gainSlewDB — на сколько dB в секунду усиление может измениться по ходу клипа (без «качания» громкости)
+	gainSlewDB = 2.0
+	// Окна ограничителя: упреждение (плавно убрать усиление до пика) и восстановление после пика
+	limiterLookahead = 5 * time.Millisecond
+	limiterRelease   = 80 * time.Millisecond
+)
+
+// apply измеряет начало клипа и возвращает поток, выровненный по громкости, с ограничителем пиков.
+func (l Loudness) apply(s beep.StreamSeekCloser, rate beep.SampleRate) (beep.StreamSeekCloser, error) {
+	lv := &leveler{
+		src:       s,
+		l:         l,
+		rate:      rate,
+		meter:     newLoudnessMeter(rate),
+		lookahead: max(1, rate.N(limiterLookahead)),
+		attack:    1 / float64(max(1, rate.N(limiterLookahead))),
+		release:   1 / float64(max(1, rate.N(limiterRelease))),
+		ceiling:   dbToGain(min(0, l.CeilingDB)),
+		env:       1,
+	}
+	lv.fill(rate.N(measureWindow))
+	if len(lv.pending) == 0 {
+		err := s.Err()
+		_ = s.Close()
+		if err == nil {
+			err = errors.New("empty clip")
+		}
+		return nil, err
+	}
+	lv.gainDB = lv.targetDB()
+	return lv, nil
+}
+
+// leveler — клип, выровненный по громкости: усиление выбирается по началу клипа и плавно уточняется
+// по мере проигрывания; пики прижимаются к потолку с упреждением на limiterLookahead.
+type leveler struct {
+	src     beep.StreamSeekCloser
+	l       Loudness
+	rate    beep.SampleRate
+	meter   *loudnessMeter
+	pending [][2]float64 // прочитанные, но ещё не отданные сэмплы (окно упреждения)
+	ended   bool
+	pos     int
+
+	lookahead       int
+	attack, release float64 // шаг огибающей ограничителя на сэмпл
+	ceiling         float64
+	gainDB          float64 // текущее усиление
+	env             float64 // множитель ограничителя на последнем отданном сэмпле
+	gains, envs     []float64
+	chunk           [][2]float64
+}
+
+// fill дочитывает источник, пока в pending не наберётся need сэмплов или клип не кончится.
+func (lv *leveler) fill(need int) {
+	if lv.chunk == nil {
+		lv.chunk = make([][2]float64, 4096)
+	}
+	for len(lv.pending) < need && !lv.ended {
+		n, ok := lv.src.Stream(lv.chunk[:min(len(lv.chunk), need-len(lv.pending))])
+		lv.pending = append(lv.pending, lv.chunk[:n]...)
+		lv.meter.add(lv.chunk[:n])
+		if !ok {
+			lv.ended = true
+		}
+	}
+}
+
+// targetDB — усиление до целевой громкости по измеренному на данный момент; тишина — без усиления.
+func (lv *leveler) targetDB() float64 {
+	lufs := lv.meter.integrated()
+	if math.IsInf(lufs, -1) {
+		return 0
+	}
+	return min(lv.l.TargetLUFS-lufs, lv.l.MaxGainDB)
+}
+
+func (lv *leveler) Stream(samples [][2]float64) (int, bool) {
+	lv.fill(len(samples) + lv.lookahead)
+	n := min(len(samples), len(lv.pending))
+	if n == 0 {
+		return 0, false
+	}
+	// Множитель на отдаваемых сэмплах зависит только от окна упреждения за ними — дальше не считаем
+	m := min(len(lv.pending), n+lv.lookahead)
+	if cap(lv.gains) < m {
+		lv.gains, lv.envs = make([]float64, m), make([]float64, m)
+	}
+	gains, envs := lv.gains[:m], lv.envs[:m]
+
+	// Усиление каждого сэмпла плавно идёт к цели; для окна упреждения — продолжение того же хода
+	target := lv.targetDB()
+	step := gainSlewDB / float64(lv.rate)
+	db, next := lv.gainDB, lv.gainDB
+	for i := range m {
+		db += max(-step, min(step, target-db))
+		gains[i] = dbToGain(db)
+		if i == n-1 {
+			next = db
+		}
+	}
+	limitEnvelope(lv.pending[:m], gains, envs, lv.ceiling, lv.attack, lv.release, lv.env)
+	for i := range n {
+		g := gains[i] * envs[i]
+		samples[i] = [2]float64{lv.pending[i][0] * g, lv.pending[i][1] * g}
+	}
+	lv.gainDB, lv.env = next, envs[n-1]
+	lv.pending = lv.pending[n:] // хвост массива переиспользует append в fill — окно не копируется на каждый вызов
+	lv.pos += n
+	return n, true
+}
+
+func (lv *leveler) Err() error { return lv.src.Err() }
+
+func (lv *leveler) Len() int { return lv.src.Len() }
+
+func (lv *leveler) Position() int { return lv.pos }
+
+func (lv *leveler) Seek(int) error { return errors.New("seek is not supported") }
+
+func (lv *leveler) Close() error { return lv.src.Close() }
+
+// limitEnvelope считает множитель ограничителя: усиление снижается заранее (упреждение) и восстанавливается
+// постепенно, поэтому пики не срезаются и не щёлкают. prev — множитель на сэмпле перед samples.
+func limitEnvelope(samples [][2]float64, gains, envs []float64, ceiling, attack, release, prev float64) {
+	for i, s := range samples {
+		peak := max(math.Abs(s[0]), math.Abs(s[1])) * gains[i]
+		envs[i] = 1
+		if peak > ceiling {
+			envs[i] = ceiling / peak
+		}
+	}
+	// Упреждение: за lookahead до пика множитель линейно опускается до нужного
+	for i := len(envs) - 2; i >= 0; i-- {
+		envs[i] = min(envs[i], envs[i+1]+attack)
+	}
+	// Восстановление: после пика множитель растёт не быстрее release
+	for i := range envs {
+		envs[i] = min(envs[i], prev+release)
+		prev = envs[i]
+	}
+}
+
+// loudnessMeter — интегральная громкость по ITU-R BS.1770-4, считается по мере поступления сэмплов:
+// K-фильтр, блоки 400 мс с шагом 100 мс, абсолютный порог -70 LUFS и относительный -10 LU.
+type loudnessMeter struct {
+	f      [2]kFilter
+	step   int       // сэмплов в шаге 100 мс
+	sum    float64   // сумма квадратов текущего шага
+	k      int       // сэмплов в текущем шаге
+	steps  []float64 // средний квадрат K-взвешенного сигнала по шагам (сумма каналов)
+	blocks []float64 // блоки по 4 шага с перекрытием 75%
+}
+
+func newLoudnessMeter(rate beep.SampleRate) *loudnessMeter {
+	m := &loudnessMeter{step: max(1, rate.N(100*time.Millisecond))}
+	for c := range 2 {
+		m.f[c] = newKFilter(float64(rate))
+	}
+	return m
+}
+
+func (m *loudnessMeter) add(samples [][2]float64) {
+	for _, s := range samples {
+		for c := range 2 {
+			y := m.f[c].process(s[c])
+			m.sum += y * y
+		}
+		if m.k++; m.k == m.step {
+			m.steps = append(m.steps, m.sum/float64(m.step))
+			m.sum, m.k = 0, 0
+			if n := len(m.steps); n >= 4 {
+				m.blocks = append(m.blocks, mean(m.steps[n-4:]))
+			}
+		}
+	}
+}
+
+// integrated — громкость всего измеренного; тишина — -Inf. Клип короче 400 мс — один блок из того, что есть.
+func (m *loudnessMeter) integrated() float64 {
+	blocks := m.blocks
+	if len(blocks) == 0 {
+		steps := m.steps
+		if m.k > 0 {
+			steps = append(slices.Clip(steps), m.sum/float64(m.k))
+		}
+		if len(steps) == 0 {
+			return math.Inf(-1)
+		}
+		blocks = []float64{mean(steps)}
+	}
+	gated := func(threshold float64) []float64 {
+		var out []float64
+		for _, z := range blocks {
+			if lufs(z) > threshold {
+				out = append(out, z)
+			}
+		}
+		return out
+	}
+	abs := gated(-70)
+	if len(abs) == 0 {
+		return math.Inf(-1)
+	}
+	rel := gated(lufs(mean(abs)) - 10)
+	if len(rel) == 0 {
+		return math.Inf(-1)
+	}
+	return lufs(mean(rel))
+}
+
+func lufs(z float64) float64 { return -0.691 + 10*math.Log10(z) }
+
+func mean(v []float64) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	s := 0.0
+	for _, x := range v {
+		s += x
+	}
+	return s / float64(len(v))
+}
+
+// kFilter — K-взвешивание BS.1770: полка +4 dB выше ~1.7 кГц и срез ниже ~38 Гц (две биквадратные секции).
+type kFilter struct {
+	shelf, highpass biquad
+}
+
+func newKFilter(fs float64) kFilter {
+	// Коэффициенты для произвольной частоты дискретизации (как в libebur128)
+	const (
+		shelfF0 = 1681.974450955533
+		shelfG  = 3.999843853973347
+		shelfQ  = 0.7071752369554196
+		hpF0    = 38.13547087602444
+		hpQ     = 0.5003270373238773
+	)
+	k := math.Tan(math.Pi * shelfF0 / fs)
+	vh := math.Pow(10, shelfG/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+	k = math.Tan(math.Pi * hpF0 / fs)
+	a0 = 1 + k/hpQ + k*k
+	highpass := biquad{
+		b0: 1, b1: -2, b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/hpQ + k*k) / a0,
+	}
+	return kFilter{shelf: shelf, highpass: highpass}
+}
+
+func (f *kFilter) process(x float64) float64 { return f.highpass.process(f.shelf.process(x)) }
+
+// biquad — биквадратный фильтр (прямая форма I).
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (b *biquad) process(x float64) float64 {
+	y := b.b0*x + b.b1*b.x1 + b.b2*b.x2 - b.a1*b.y1 - b.a2*b.y2
+	b.x2, b.x1 = b.x1, x
+	b.y2, b.y1 = b.y1, y
+	return y
+}
@@ -0,0 +1,142 @@
+package player
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+const testRate = beep.SampleRate(48000)
+
+// samplesClip — клип из готовых сэмплов.
+type samplesClip struct {
+	s      [][2]float64
+	pos    int
+	closed bool
+}
+
+func (c *samplesClip) Stream(out [][2]float64) (int, bool) {
+	if c.pos >= len(c.s) {
+		return 0, false
+	}
+	n := copy(out, c.s[c.pos:])
+	c.pos += n
+	return n, true
+}
+
+func (c *samplesClip) Err() error       { return nil }
+func (c *samplesClip) Len() int         { return len(c.s) }
+func (c *samplesClip) Position() int    { return c.pos }
+func (c *samplesClip) Seek(p int) error { c.pos = p; return nil }
+func (c *samplesClip) Close() error     { c.closed = true; return nil }
+
+// sine — синус freq Гц с амплитудой amp в левом канале и, если stereo, в правом.
+func sine(freq, amp float64, d int, stereo bool) [][2]float64 {
+	out := make([][2]float64, d)
+	for i := range out {
+		v := amp * math.Sin(2*math.Pi*freq*float64(i)/float64(testRate))
+		out[i][0] = v
+		if stereo {
+			out[i][1] = v
+		}
+	}
+	return out
+}
+
+// streamAll вычитывает поток буферами size сэмплов.
+func streamAll(s beep.Streamer, size int) [][2]float64 {
+	var out [][2]float64
+	buf := make([][2]float64, size)
+	for {
+		n, ok := s.Stream(buf)
+		out = append(out, buf[:n]...)
+		if !ok {
+			return out
+		}
+	}
+}
+
+func measure(samples [][2]float64) float64 {
+	m := newLoudnessMeter(testRate)
+	m.add(samples)
+	return m.integrated()
+}
+
+func TestLoudnessMeter_ReferenceSine(t *testing.T) {
+	// BS.1770: синус 1 кГц 0 dBFS в одном канале — -3.01 LUFS; в обоих — 0 LUFS
+	tests := []struct {
+		name   string
+		amp    float64
+		stereo bool
+		want   float64
+	}{
+		{"full scale mono", 1, false, -3.01},
+		{"full scale stereo", 1, true, 0},
+		{"-20 dBFS stereo", 0.1, true, -20},
+		{"-40 dBFS stereo", 0.01, true, -40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := measure(sine(1000, tt.amp, testRate.N(3e9), tt.stereo))
+			if math.Abs(got-tt.want) > 0.1 {
+				t.Fatalf("integrated = %.2f LUFS, want %.2f", got, tt.want)
+			}
+		})
+	}
+	if got := measure(make([][2]float64, testRate.N(1e9))); !math.IsInf(got, -1) {
+		t.Fatalf("silence = %.2f LUFS, want -Inf", got)
+	}
+}
+
+func TestLoudness_ReachesTarget(t *testing.T) {
+	l := Loudness{Enabled: true, TargetLUFS: -16, MaxGainDB: 12, CeilingDB: -1}
+	clip := sine(1000, 0.05, testRate.N(4e9), true) // около -26 LUFS
+	s, err := l.apply(&samplesClip{s: clip}, testRate)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	out := streamAll(s, 512)
+	if len(out) != len(clip) {
+		t.Fatalf("got %d samples, want %d", len(out), len(clip))
+	}
+	if got := measure(out); math.Abs(got-l.TargetLUFS) > 0.5 {
+		t.Fatalf("output = %.2f LUFS, want %.1f", got, l.TargetLUFS)
+	}
+}
+
+func TestLoudness_PeaksStayBelowCeiling(t *testing.T) {
+	l := Loudness{Enabled: true, TargetLUFS: -16, MaxGainDB: 12, CeilingDB: -1}
+	ceiling := dbToGain(l.CeilingDB)
+	// Тихая речь с резкими щелчками: усиление поднимает всё, а щелчки упираются в потолок
+	clip := sine(220, 0.05, testRate.N(3e9), true)
+	for i := 1000; i < len(clip); i += 7919 {
+		clip[i] = [2]float64{0.9, -0.9}
+	}
+	for _, size := range []int{7, 333, 4096} { // пики на границах буферов тоже
+		s, err := l.apply(&samplesClip{s: clip}, testRate)
+		if err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+		for i, v := range streamAll(s, size) {
+			if peak := max(math.Abs(v[0]), math.Abs(v[1])); peak > ceiling+1e-9 {
+				t.Fatalf("buffer %d: sample %d peak %.4f above ceiling %.4f", size, i, peak, ceiling)
+			}
+		}
+	}
+}
+
+func TestLoudness_MaxGainAndEmptyClip(t *testing.T) {
+	l := Loudness{Enabled: true, TargetLUFS: -16, MaxGainDB: 6, CeilingDB: -1}
+	clip := sine(1000, 0.001, testRate.N(3e9), true) // -60 LUFS: нужно +44 dB, разрешено +6
+	s, err := l.apply(&samplesClip{s: clip}, testRate)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got := measure(streamAll(s, 1024)); math.Abs(got-(-60+6)) > 0.5 {
+		t.Fatalf("output = %.2f LUFS, want -54 (gain capped at MaxGainDB)", got)
+	}
+	if _, err := l.apply(&samplesClip{}, testRate); err == nil {
+		t.Fatalf("empty clip: want error")
+	}
+}
@@ -0,0 +1,123 @@
+package player
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/faiface/beep"
+	"github.com/pion/opus"
+	"github.com/pion/opus/pkg/oggreader"
+)
+
+// opusRate — Opus всегда декодируется в 48 кГц; частота из заголовка — лишь частота исходника.
+const opusRate = 48000
+
+// maxOpusFrame — самый длинный пакет Opus: 120 мс при 48 кГц.
+const maxOpusFrame = opusRate * 120 / 1000
+
+// opusStream декодирует Ogg Opus по мере проигрывания, пакет за пакетом.
+type opusStream struct {
+	ogg      *oggreader.OggReader
+	dec      opus.Decoder
+	closer   io.Closer
+	channels int
+	gain     float64 // выходное усиление из заголовка OpusHead
+	skip     int     // pre-skip: сэмплы разгона декодера в начале потока
+
+	buf      []float32 // декодированный пакет, каналы чередуются
+	pos, n   int       // позиция и число сэмплов на канал в buf
+	position int
+	err      error
+}
+
+// decodeOpus открывает поток Ogg Opus (моно или стерео).
+func decodeOpus(r io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	ogg, head, err := oggreader.NewWith(r)
+	if err != nil {
+		_ = r.Close()
+		return nil, beep.Format{}, fmt.Errorf("ogg/opus: %w", err)
+	}
+	channels := int(head.Channels)
+	if channels != 1 && channels != 2 {
+		_ = r.Close()
+		return nil, beep.Format{}, fmt.Errorf("ogg/opus: unsupported channel count %d", channels)
+	}
+	dec, err := opus.NewDecoderWithOutput(opusRate, channels)
+	if err != nil {
+		_ = r.Close()
+		return nil, beep.Format{}, fmt.Errorf("ogg/opus: %w", err)
+	}
+	s := &opusStream{
+		ogg:      ogg,
+		dec:      dec,
+		closer:   r,
+		channels: channels,
+		gain:     math.Pow(10, float64(int16(head.OutputGain))/256/20), // Q7.8 dB
+		skip:     int(head.PreSkip),
+		buf:      make([]float32, maxOpusFrame*channels),
+	}
+	return s, beep.Format{SampleRate: opusRate, NumChannels: 2, Precision: 2}, nil
+}
+
+func (s *opusStream) Stream(samples [][2]float64) (int, bool) {
+	filled := 0
+	for filled < len(samples) {
+		if s.pos >= s.n && !s.next() {
+			break
+		}
+		for ; s.pos < s.n && filled < len(samples); s.pos++ {
+			l := float64(s.buf[s.pos*s.channels]) * s.gain
+			r := l
+			if s.channels == 2 {
+				r = float64(s.buf[s.pos*2+1]) * s.gain
+			}
+			samples[filled] = [2]float64{l, r}
+			filled++
+		}
+	}
+	s.position += filled
+	return filled, filled > 0
+}
+
+// next декодирует следующий звуковой пакет; false — поток кончился или ошибка (см. Err).
+func (s *opusStream) next() bool {
+	for {
+		packet, _, err := s.ogg.ParseNextPacket()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.err = fmt.Errorf("ogg/opus: %w", err)
+			}
+			return false
+		}
+		if bytes.HasPrefix(packet, []byte("OpusTags")) {
+			continue
+		}
+		n, err := s.dec.DecodeToFloat32(packet, s.buf)
+		if err != nil {
+			s.err = fmt.Errorf("ogg/opus: %w", err)
+			return false
+		}
+		s.pos, s.n = 0, n
+		if s.skip > 0 {
+			d := min(s.skip, n)
+			s.pos, s.skip = d, s.skip-d
+		}
+		if s.pos < s.n {
+			return true
+		}
+	}
+}
+
+func (s *opusStream) Err() error { return s.err }
+
+// Len неизвестна: поток читается из сети, не целиком.
+func (s *opusStream) Len() int { return 0 }
+
+func (s *opusStream) Position() int { return s.position }
+
+func (s *opusStream) Seek(int) error { return errors.New("ogg/opus: seek is not supported") }
+
+func (s *opusStream) Close() error { return s.closer.Close() }
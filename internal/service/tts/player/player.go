@@ -1,84 +1,330 @@
 package player
 
 import (
-	"errors"
+	"context"
 	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/faiface/beep"
-	"github.com/faiface/beep/effects"
-	"github.com/faiface/beep/mp3"
-	"github.com/faiface/beep/speaker"
-	"github.com/faiface/beep/wav"
+	"go.uber.org/zap"
 )
 
+// Качество ресемплинга beep: 4 — хороший баланс для on-the-fly
+const resampleQuality = 4
+
+// deviceBuffer — буфер звуковой карты: настолько звук отстаёт от момента, когда микшер отдал сэмплы
+const deviceBuffer = time.Second / 10
+
 // Player воспроизводит аудио потоком в зависимости от формата.
 type Player interface {
-	Play(format string, r io.ReadCloser) error
+	// Play блокируется до конца клипа; отмена ctx плавно глушит клип (Options.FadeOut) и возвращает причину отмены.
+	Play(ctx context.Context, format string, r io.ReadCloser, opts Options) error
+	// PlayQueue проигрывает клипы из канала по порядку без пауз между ними, как один клип с параметрами opts.
+	// Следующий клип начинает звучать сразу за предыдущим, если уже готов; иначе до его прихода звучит тишина.
+	// Блокируется, пока канал не закрыт и всё не доиграно; отмена ctx — как у Play.
+	PlayQueue(ctx context.Context, clips <-chan Clip, opts Options) error
+}
+
+// Clip — клип очереди PlayQueue.
+type Clip struct {
+	Format string
+	R      io.ReadCloser
+	GainDB float64 // усиление этого клипа поверх Options.GainDB (провайдеры в очереди могут отличаться)
+	Meta   Meta    // текст и провайдер куска для выхода files
+
+	// OnStart вызывается, когда клип начинает звучать; d — его длительность (0 — неизвестна)
+	OnStart func(d time.Duration)
+}
+
+// Kind — категория клипа при микшировании.
+type Kind int
+
+const (
+	KindSpeech Kind = iota // речь TTS
+	KindEffect             // уведомления и эффекты: пока звучат, речь приглушается (ducking)
+)
+
+// Options — параметры одного воспроизведения.
+type Options struct {
+	Kind    Kind
+	GainDB  float64       // усиление клипа в dB (отрицательные — тише)
+	FadeOut time.Duration // затухание при отмене ctx; 0 — мгновенная остановка
+	Meta    Meta          // описание речи для выхода files (у PlayQueue — в каждом Clip)
+
+	// OnStart вызывается, когда клип начинает звучать; d — длительность клипа (0 — неизвестна)
+	OnStart func(d time.Duration)
+}
+
+// MixerConfig — параметры общего аудиовыхода.
+type MixerConfig struct {
+	SampleRate int      // частота выхода; клипы с другой частотой ресемплируются
+	DuckDB     float64  // приглушение речи на время эффектов, dB
+	Sinks      []string // выходы Sink*; пусто — только звуковая карта
+	RecordDir  string   // папка записей files (подпапка clips) и session
+	Loudness   Loudness // выравнивание громкости речи и уведомлений
+	Logger     *zap.SugaredLogger
+}
+
+// Mixer — единственный долгоживущий аудиовыход приложения: все клипы смешиваются в нём.
+type Mixer struct {
+	rate     beep.SampleRate
+	duckGain float64
+	loudness Loudness
+
+	initOnce  sync.Once
+	initErr   error
+	mix       *beep.Mixer
+	device    bool          // выводить на звуковую карту; иначе микшер тактирует таймер
+	clips     *clipFiles    // SinkFiles; nil — выключен
+	session   *sessionTrack // SinkSession; nil — выключен
+	out       *tap
+	env       *envelope // огибающая речи для липсинка
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	effects int // активные клипы KindEffect; читается и меняется под lock
+}
+
+// NewMixer создаёт микшер. Аудиоустройство и записи открываются при первом Play.
+func NewMixer(cfg MixerConfig) *Mixer {
+	rate := cfg.SampleRate
+	if rate <= 0 {
+		rate = 48000
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop().Sugar()
+	}
+	m := &Mixer{
+		rate:     beep.SampleRate(rate),
+		duckGain: dbToGain(min(0, cfg.DuckDB)),
+		loudness: cfg.Loudness,
+		mix:      &beep.Mixer{},
+		device:   len(cfg.Sinks) == 0,
+		closed:   make(chan struct{}),
+	}
+	for _, name := range cfg.Sinks {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case SinkSpeaker:
+			m.device = true
+		case SinkFiles:
+			m.clips = &clipFiles{dir: filepath.Join(cfg.RecordDir, "clips"), logger: logger}
+		case SinkSession:
+			m.session = newSessionTrack(cfg.RecordDir, m.rate, logger)
+		}
+	}
+	m.out = &tap{mix: m.mix}
+	m.env = &envelope{}
+	if m.device {
+		m.env.latency = deviceBuffer
+	}
+	return m
 }
 
-// Default реализует Player и поддерживает mp3 и wav.
-type Default struct{ volumeDB float64 }
+// init открывает устройство и запись сессии один раз и запускает микшер.
+func (m *Mixer) init() error {
+	m.initOnce.Do(func() {
+		if m.session != nil {
+			if m.initErr = m.session.open(); m.initErr != nil {
+				return
+			}
+			m.out.session = m.session
+		}
+		if !m.device {
+			go m.clock(m.out)
+			return
+		}
+		if m.initErr = deviceInit(m.rate, m.rate.N(deviceBuffer)); m.initErr != nil {
+			return
+		}
+		devicePlay(m.out)
+	})
+	return m.initErr
+}
 
-// New создаёт плеер без изменения громкости (0 dB).
-func New() *Default { return &Default{volumeDB: 0} }
+// Close останавливает таймер выхода без звуковой карты и дописывает запись сессии.
+func (m *Mixer) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closed)
+		lock()
+		m.out.session = nil
+		unlock()
+		if m.session != nil {
+			m.session.close()
+		}
+	})
+	return nil
+}
 
-// NewWithVolume создаёт плеер с предустановленной громкостью в dB (отрицательные — тише).
-func NewWithVolume(db float64) *Default { return &Default{volumeDB: db} }
+// open декодирует клип (с записью в файлы, если включена) и выравнивает его громкость.
+func (m *Mixer) open(kind Kind, format string, gainDB float64, meta Meta, r io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	streamer, bf, err := decode(format, m.record(kind, format, gainDB, meta, r))
+	if err != nil || !m.loudness.Enabled {
+		return streamer, bf, err
+	}
+	leveled, err := m.loudness.apply(streamer, bf.SampleRate)
+	return leveled, bf, err
+}
 
-func (d *Default) Play(format string, r io.ReadCloser) error {
-	switch format {
-	case "wav", "WAV":
-		return playWAV(r, d.volumeDB)
-	case "mp3", "MP3":
-		return playMP3(r, d.volumeDB)
-	default:
-		return errors.New("unsupported format for direct playback; use mp3 or wav")
+// record подключает запись клипа речи в файлы, если выход files включён.
+func (m *Mixer) record(kind Kind, format string, gainDB float64, meta Meta, r io.ReadCloser) io.ReadCloser {
+	if m.clips == nil || kind != KindSpeech {
+		return r
 	}
+	return m.clips.record(format, gainDB, meta, r)
 }
 
-func playWAV(r io.ReadCloser, volDB float64) error {
-	streamer, format, err := wav.Decode(r)
+// Play декодирует клип, приводит к частоте выхода и добавляет в микшер.
+func (m *Mixer) Play(ctx context.Context, format string, r io.ReadCloser, opts Options) error {
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+	if err := m.init(); err != nil {
+		return err
+	}
+	streamer, bf, err := m.open(opts.Kind, format, opts.GainDB, opts.Meta, r)
 	if err != nil {
 		return err
 	}
 	defer streamer.Close()
 
-	if err := speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10)); err != nil {
-		return err
+	var d time.Duration
+	if n := streamer.Len(); n > 0 {
+		d = bf.SampleRate.D(n)
 	}
-	// Интерпретируем volDB как децибелы: линейный коэффициент = 10^(dB/20)
-	vol := &effects.Volume{
-		Streamer: streamer,
-		Base:     10,
-		Volume:   volDB / 20.0,
-		Silent:   false,
+	return m.run(ctx, m.resample(streamer, bf), opts, func() {
+		if opts.OnStart != nil {
+			opts.OnStart(d)
+		}
+	})
+}
+
+// resample приводит поток к частоте выхода.
+func (m *Mixer) resample(s beep.Streamer, bf beep.Format) beep.Streamer {
+	if bf.SampleRate != m.rate {
+		return beep.Resample(resampleQuality, bf.SampleRate, m.rate, s)
 	}
-	done := make(chan struct{})
-	speaker.Play(beep.Seq(vol, beep.Callback(func() { close(done) })))
-	<-done
-	return nil
+	return s
 }
 
-func playMP3(r io.ReadCloser, volDB float64) error {
-	streamer, format, err := mp3.Decode(r)
-	if err != nil {
-		return err
+// run добавляет источник в микшер и ждёт его окончания или отмены ctx; started вызывается сразу после добавления.
+func (m *Mixer) run(ctx context.Context, src beep.Streamer, opts Options, started func()) error {
+	c := &clip{
+		m:    m,
+		src:  src,
+		kind: opts.Kind,
+		gain: dbToGain(opts.GainDB),
+		duck: 1,
+		fade: 1,
+		done: make(chan struct{}),
+	}
+	if c.kind == KindSpeech {
+		c.meter = newMeter(m.env, m.rate)
 	}
-	defer streamer.Close()
 
-	if err := speaker.Init(format.SampleRate, format.SampleRate.N(time.Second/10)); err != nil {
-		return err
+	lock()
+	if c.kind == KindEffect {
+		m.effects++
 	}
-	// Интерпретируем volDB как децибелы: линейный коэффициент = 10^(dB/20)
-	vol := &effects.Volume{
-		Streamer: streamer,
-		Base:     10,
-		Volume:   volDB / 20.0,
-		Silent:   false,
+	m.mix.Add(c)
+	unlock()
+	if started != nil {
+		started()
 	}
-	done := make(chan struct{})
-	speaker.Play(beep.Seq(vol, beep.Callback(func() { close(done) })))
-	<-done
-	return nil
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Отмена: затухание или мгновенная остановка
+	lock()
+	if n := m.rate.N(opts.FadeOut); n > 0 {
+		c.fadeStep = 1 / float64(n)
+	} else {
+		c.stopped = true
+	}
+	unlock()
+	select {
+	case <-c.done:
+	case <-time.After(opts.FadeOut + time.Second):
+		// устройство не забирает сэмплы — снимаем клип сами
+		lock()
+		c.stopped = true
+		c.finish()
+		unlock()
+	}
+	return context.Cause(ctx)
 }
+
+// clip — источник в микшере с усилением, приглушением и затуханием.
+// Все поля меняются только под lock (Stream вызывается устройством под ним же).
+type clip struct {
+	m        *Mixer
+	src      beep.Streamer
+	kind     Kind
+	gain     float64 // линейное усиление клипа
+	duck     float64 // текущий множитель приглушения (сглаживается к целевому)
+	fade     float64 // множитель затухания 1..0
+	fadeStep float64 // уменьшение fade на сэмпл; 0 — без затухания
+	meter    *meter  // огибающая для липсинка (только речь)
+	stopped  bool
+
+	done chan struct{}
+	once sync.Once
+}
+
+// Скорость сглаживания приглушения на сэмпл (~20 мс при 48 кГц), чтобы не было щелчков
+const duckSmoothing = 0.001
+
+func (c *clip) Stream(samples [][2]float64) (int, bool) {
+	if c.stopped {
+		c.finish()
+		return 0, false
+	}
+	n, ok := c.src.Stream(samples)
+	target := 1.0
+	if c.kind == KindSpeech && c.m.effects > 0 {
+		target = c.m.duckGain
+	}
+	for i := range samples[:n] {
+		c.duck += (target - c.duck) * duckSmoothing
+		if c.fadeStep > 0 {
+			if c.fade -= c.fadeStep; c.fade <= 0 {
+				c.fade = 0
+				c.stopped = true
+			}
+		}
+		g := c.gain * c.duck * c.fade
+		samples[i][0] *= g
+		samples[i][1] *= g
+	}
+	if c.meter != nil {
+		c.meter.add(samples[:n])
+	}
+	if c.stopped || !ok {
+		c.finish()
+		return n, false
+	}
+	return n, true
+}
+
+func (c *clip) Err() error { return c.src.Err() }
+
+// finish помечает клип завершённым; вызывается под lock.
+func (c *clip) finish() {
+	c.once.Do(func() {
+		if c.kind == KindEffect {
+			c.m.effects--
+		}
+		close(c.done)
+	})
+}
+
+// dbToGain переводит децибелы в линейный коэффициент: 10^(dB/20).
+func dbToGain(db float64) float64 { return math.Pow(10, db/20) }
@@ -0,0 +1,221 @@
+package player
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// level — клип из n одинаковых сэмплов v: по значению видно, чей это кусок, а ноль в середине — пауза.
+func level(n int, v float64) *samplesClip {
+	s := make([][2]float64, n)
+	for i := range s {
+		s[i] = [2]float64{v, -v}
+	}
+	return &samplesClip{s: s}
+}
+
+func TestQueue_PlaysInOrderWithoutGaps(t *testing.T) {
+	for _, size := range []int{7, 333, 4096} {
+		clips := []*samplesClip{level(1000, 0.1), level(1, 0.2), level(0, 0.9), level(2500, 0.3)}
+		q := &queue{started: make(chan queued, len(clips)), closed: true}
+		var want [][2]float64
+		for _, c := range clips {
+			q.pending = append(q.pending, queued{src: c, closer: c, gain: 1})
+			want = append(want, c.s...)
+		}
+
+		got := streamAll(q, size)
+		if len(got) != len(want) {
+			t.Fatalf("buffer %d: got %d samples, want %d", size, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("buffer %d: sample %d = %v, want %v", size, i, got[i], want[i])
+			}
+		}
+		for i, c := range clips {
+			if !c.closed {
+				t.Errorf("buffer %d: clip %d not closed after playback", size, i)
+			}
+			if it := <-q.started; it.closer != c {
+				t.Errorf("buffer %d: clip %d started out of order", size, i)
+			}
+		}
+	}
+}
+
+func TestQueue_SilenceUntilNextClip(t *testing.T) {
+	q := &queue{started: make(chan queued, 1)}
+	buf := make([][2]float64, 100)
+	buf[0] = [2]float64{1, 1}
+	if n, ok := q.Stream(buf); n != len(buf) || !ok || buf[0] != [2]float64{} {
+		t.Fatalf("empty open queue: n=%d ok=%v first=%v, want silence", n, ok, buf[0])
+	}
+	c := level(30, 0.5)
+	q.pending = append(q.pending, queued{src: c, closer: c, gain: 1})
+	q.closed = true
+	if n, ok := q.Stream(buf); n != 30 || !ok || buf[29] != c.s[29] {
+		t.Fatalf("last clip: n=%d ok=%v, want 30 samples", n, ok)
+	}
+	if n, ok := q.Stream(buf); n != 0 || ok {
+		t.Fatalf("drained closed queue: n=%d ok=%v, want end of stream", n, ok)
+	}
+}
+
+// tracked — поток клипа, который запоминает закрытие.
+type tracked struct {
+	io.ReadCloser
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (r *tracked) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return r.ReadCloser.Close()
+}
+
+func (r *tracked) isClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// wavClip пишет WAV длительностью d с постоянным уровнем v и открывает его для очереди.
+func wavClip(t *testing.T, d time.Duration, v float64) *tracked {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "clip-*.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := testRate.N(d)
+	pcm := make([]byte, n*4)
+	for i := range n {
+		binary.LittleEndian.PutUint16(pcm[i*4:], uint16(toInt16(v)))
+		binary.LittleEndian.PutUint16(pcm[i*4+2:], uint16(toInt16(v)))
+	}
+	if err := writeWAVHeader(f, testRate, int64(len(pcm))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt(pcm, 44); err != nil { // writeWAVHeader пишет по смещению 0
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return &tracked{ReadCloser: f, closed: make(chan struct{})}
+}
+
+func discardMixer(t *testing.T) *Mixer {
+	t.Helper()
+	m := NewMixer(MixerConfig{SampleRate: int(testRate), Sinks: []string{SinkDiscard}})
+	t.Cleanup(func() { _ = m.Close() })
+	return m
+}
+
+func TestMixer_PlayQueueInOrder(t *testing.T) {
+	const clipLen = 100 * time.Millisecond
+	m := discardMixer(t)
+
+	var mu sync.Mutex
+	var order []int
+	var starts []time.Time
+	clips := make(chan Clip, 3)
+	var readers []*tracked
+	for i := range 3 {
+		r := wavClip(t, clipLen, 0.1*float64(i+1))
+		readers = append(readers, r)
+		clips <- Clip{Format: "wav", R: r, OnStart: func(d time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			if d != clipLen {
+				t.Errorf("clip %d: OnStart duration %s, want %s", i, d, clipLen)
+			}
+			order = append(order, i)
+			starts = append(starts, time.Now())
+		}}
+	}
+	close(clips)
+
+	began := time.Now()
+	if err := m.PlayQueue(context.Background(), clips, Options{}); err != nil {
+		t.Fatalf("PlayQueue: %v", err)
+	}
+	// Дискард тактирует выход в реальном темпе: без пауз очередь звучит столько же, сколько клипы подряд
+	if elapsed := time.Since(began); elapsed < 3*clipLen-clockTick || elapsed > 3*clipLen+250*time.Millisecond {
+		t.Errorf("queue played for %s, want about %s", elapsed, 3*clipLen)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 3 || order[0] != 0 || order[1] != 1 || order[2] != 2 {
+		t.Fatalf("start order = %v, want [0 1 2]", order)
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap > clipLen+100*time.Millisecond {
+			t.Errorf("clip %d started %s after clip %d, want about %s", i, gap, i-1, clipLen)
+		}
+	}
+	for i, r := range readers {
+		if !r.isClosed() {
+			t.Errorf("clip %d not closed after playback", i)
+		}
+	}
+}
+
+func TestMixer_PlayQueueCancel(t *testing.T) {
+	m := discardMixer(t)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	stop := errors.New("barge-in")
+	defer time.AfterFunc(5*time.Second, func() { cancel(errors.New("first clip never started")) }).Stop()
+
+	clips := make(chan Clip, 4)
+	var readers []*tracked
+	for i := range 3 {
+		r := wavClip(t, 5*time.Second, 0.2)
+		readers = append(readers, r)
+		c := Clip{Format: "wav", R: r}
+		if i == 0 {
+			c.OnStart = func(time.Duration) { cancel(stop) }
+		}
+		clips <- c
+	}
+
+	began := time.Now()
+	err := m.PlayQueue(ctx, clips, Options{FadeOut: 20 * time.Millisecond})
+	if !errors.Is(err, stop) {
+		t.Fatalf("err = %v, want cancel cause", err)
+	}
+	if elapsed := time.Since(began); elapsed > time.Second {
+		t.Fatalf("cancel took %s, want playback stopped right away", elapsed)
+	}
+
+	// Клип, пришедший уже после отмены, тоже закрывается
+	late := wavClip(t, time.Second, 0.2)
+	readers = append(readers, late)
+	clips <- Clip{Format: "wav", R: late}
+	close(clips)
+
+	deadline := time.After(time.Second)
+	for i, r := range readers {
+		select {
+		case <-r.closed:
+		case <-deadline:
+			t.Fatalf("clip %d not closed after cancel", i)
+		}
+	}
+
+	lock()
+	active := m.mix.Len()
+	unlock()
+	if active != 0 {
+		t.Fatalf("mixer still has %d sources after cancel", active)
+	}
+}
@@ -0,0 +1,166 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// queued — декодированный клип очереди.
+type queued struct {
+	src     beep.Streamer
+	closer  beep.StreamSeekCloser
+	gain    float64
+	d       time.Duration
+	onStart func(d time.Duration)
+}
+
+// queue — источник микшера, склеивающий клипы подряд. Поля ниже mu-комментария меняются под lock.
+type queue struct {
+	started chan queued   // клипы, которые начали звучать: OnStart вызывается вне lock
+	fed     chan struct{} // закрывается, когда feed перестал принимать клипы
+	errs    []error       // ошибки декодирования; пишет только feed
+
+	// под lock
+	cur     *queued
+	pending []queued
+	closed  bool // новых клипов не будет
+}
+
+// PlayQueue реализует Player: клипы декодируются по мере поступления и звучат одним источником.
+func (m *Mixer) PlayQueue(ctx context.Context, clips <-chan Clip, opts Options) error {
+	if err := context.Cause(ctx); err != nil {
+		drain(clips)
+		return err
+	}
+	if err := m.init(); err != nil {
+		drain(clips)
+		return err
+	}
+	q := &queue{started: make(chan queued, 16), fed: make(chan struct{})}
+	go q.feed(ctx, m, clips, opts)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case it := <-q.started:
+				if it.onStart != nil {
+					it.onStart(it.d)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := m.run(ctx, q, opts, func() {
+		if opts.OnStart != nil {
+			opts.OnStart(0)
+		}
+	})
+	close(done)
+
+	// Отмена: недоигранные и недекодированные клипы закрываем
+	<-q.fed
+	lock()
+	if q.cur != nil {
+		_ = q.cur.closer.Close()
+		q.cur = nil
+	}
+	for _, it := range q.pending {
+		_ = it.closer.Close()
+	}
+	q.pending = nil
+	unlock()
+	drain(clips)
+
+	if err != nil {
+		return err
+	}
+	return errors.Join(q.errs...)
+}
+
+// feed декодирует клипы из канала и ставит их в очередь; битый клип пропускается с ошибкой.
+func (q *queue) feed(ctx context.Context, m *Mixer, clips <-chan Clip, opts Options) {
+	defer close(q.fed)
+	defer func() {
+		lock()
+		q.closed = true
+		unlock()
+	}()
+	for {
+		var c Clip
+		var ok bool
+		select {
+		case c, ok = <-clips:
+			if !ok {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+		streamer, bf, err := m.open(opts.Kind, c.Format, opts.GainDB+c.GainDB, c.Meta, c.R)
+		if err != nil {
+			q.errs = append(q.errs, fmt.Errorf("queue clip: %w", err))
+			continue
+		}
+		it := queued{src: m.resample(streamer, bf), closer: streamer, gain: dbToGain(c.GainDB), onStart: c.OnStart}
+		if n := streamer.Len(); n > 0 {
+			it.d = bf.SampleRate.D(n)
+		}
+		lock()
+		q.pending = append(q.pending, it)
+		unlock()
+	}
+}
+
+// Stream отдаёт сэмплы текущего клипа и сразу продолжает следующим; пока следующего нет — тишина.
+func (q *queue) Stream(samples [][2]float64) (int, bool) {
+	filled := 0
+	for filled < len(samples) {
+		if q.cur == nil {
+			if len(q.pending) == 0 {
+				if q.closed {
+					return filled, filled > 0
+				}
+				clear(samples[filled:])
+				return len(samples), true
+			}
+			it := q.pending[0]
+			q.pending = q.pending[1:]
+			q.cur = &it
+			select {
+			case q.started <- it:
+			default: // некому слушать — OnStart не критичен
+			}
+		}
+		n, ok := q.cur.src.Stream(samples[filled:])
+		for i := range samples[filled : filled+n] {
+			samples[filled+i][0] *= q.cur.gain
+			samples[filled+i][1] *= q.cur.gain
+		}
+		filled += n
+		if !ok || n == 0 {
+			_ = q.cur.closer.Close()
+			q.cur = nil
+		}
+	}
+	return filled, true
+}
+
+func (q *queue) Err() error { return nil }
+
+// drain закрывает потоки клипов, оставшихся в канале, чтобы не держать соединения и файлы.
+func drain(clips <-chan Clip) {
+	go func() {
+		for c := range clips {
+			if c.R != nil {
+				_ = c.R.Close()
+			}
+		}
+	}()
+}
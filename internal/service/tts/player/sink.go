@@ -0,0 +1,280 @@
+package player
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+	"go.uber.org/zap"
+)
+
+// Выходы звука (AUDIO_SINKS); одновременно можно включить несколько.
+const (
+	SinkSpeaker = "speaker" // звуковая карта
+	SinkFiles   = "files"   // каждый клип речи — файлом в исходном формате и описанием .json рядом
+	SinkSession = "session" // всё, что звучит, одной WAV-дорожкой за сессию
+	SinkDiscard = "discard" // никуда: для тестов и машин без звука
+)
+
+// clockTick — шаг таймера, забирающего сэмплы из микшера без звуковой карты.
+const clockTick = 10 * time.Millisecond
+
+// Meta — описание клипа для записи в файлы.
+type Meta struct {
+	Text     string `json:"text,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Persona  string `json:"persona,omitempty"`
+}
+
+// clock забирает сэмплы из микшера в реальном темпе, когда звуковой карты нет:
+// клипы длятся столько же, сколько в колонках, и barge-in с субтитрами ведут себя так же.
+func (m *Mixer) clock(out beep.Streamer) {
+	buf := make([][2]float64, m.rate.N(clockTick))
+	start := time.Now()
+	pulled := 0
+	t := time.NewTicker(clockTick)
+	defer t.Stop()
+	for {
+		select {
+		case <-m.closed:
+			return
+		case <-t.C:
+		}
+		for due := m.rate.N(time.Since(start)) - pulled; due > 0; {
+			n := min(due, len(buf))
+			lock()
+			out.Stream(buf[:n])
+			unlock()
+			due -= n
+			pulled += n
+		}
+	}
+}
+
+// tap передаёт в запись сессии то, что звучит на выходе микшера; тишина, пока ничего не играет, не пишется.
+// Stream вызывается под lock; session обнуляется под ним же при закрытии.
+type tap struct {
+	mix     *beep.Mixer
+	session *sessionTrack
+}
+
+func (t *tap) Stream(samples [][2]float64) (int, bool) {
+	active := t.mix.Len() > 0
+	n, ok := t.mix.Stream(samples)
+	if active && t.session != nil {
+		t.session.write(samples[:n])
+	}
+	return n, ok
+}
+
+func (t *tap) Err() error { return nil }
+
+// sessionTrack пишет 16-битный стерео WAV. Сэмплы копируются в канал из аудиопотока, а на диск их пишет
+// отдельная горутина; заголовок обновляется раз в секунду, так что файл читается и после аварийного выхода.
+type sessionTrack struct {
+	dir    string
+	rate   beep.SampleRate
+	logger *zap.SugaredLogger
+
+	data    chan []byte
+	done    chan struct{}
+	dropped int // блоков не записано: диск не успевал (меняется под lock)
+}
+
+func newSessionTrack(dir string, rate beep.SampleRate, logger *zap.SugaredLogger) *sessionTrack {
+	return &sessionTrack{dir: dir, rate: rate, logger: logger}
+}
+
+// open создаёт файл session-<время>.wav и запускает запись.
+func (s *sessionTrack) open() error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("session track: %w", err)
+	}
+	path := filepath.Join(s.dir, "session-"+time.Now().Format("20060102-150405")+".wav")
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("session track: %w", err)
+	}
+	if err := writeWAVHeader(f, s.rate, 0); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("session track: %w", err)
+	}
+	s.data = make(chan []byte, 256)
+	s.done = make(chan struct{})
+	go s.loop(f)
+	s.logger.Infow("Recording session audio", "path", path)
+	return nil
+}
+
+// write переводит сэмплы в PCM и отдаёт горутине записи; аудиопоток не ждёт диск.
+func (s *sessionTrack) write(samples [][2]float64) {
+	b := make([]byte, len(samples)*4)
+	for i, smp := range samples {
+		binary.LittleEndian.PutUint16(b[i*4:], uint16(toInt16(smp[0])))
+		binary.LittleEndian.PutUint16(b[i*4+2:], uint16(toInt16(smp[1])))
+	}
+	select {
+	case s.data <- b:
+	default:
+		s.dropped++
+	}
+}
+
+func (s *sessionTrack) loop(f *os.File) {
+	defer close(s.done)
+	w := bufio.NewWriterSize(f, 256<<10)
+	var size int64
+	flush := func() {
+		if err := w.Flush(); err == nil {
+			err = writeWAVHeader(f, s.rate, size)
+			if err != nil {
+				s.logger.Warnw("Session track header not updated", "error", err)
+			}
+		} else {
+			s.logger.Warnw("Session track write failed", "error", err)
+		}
+	}
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case b, ok := <-s.data:
+			if !ok {
+				flush()
+				if err := f.Close(); err != nil {
+					s.logger.Warnw("Session track not closed", "error", err)
+				}
+				return
+			}
+			if _, err := w.Write(b); err == nil {
+				size += int64(len(b))
+			}
+		case <-t.C:
+			flush()
+		}
+	}
+}
+
+// close дописывает и закрывает файл; к этому моменту tap уже не пишет.
+func (s *sessionTrack) close() {
+	if s.data == nil {
+		return
+	}
+	close(s.data)
+	<-s.done
+	if s.dropped > 0 {
+		s.logger.Warnw("Session track lost audio blocks: disk too slow", "blocks", s.dropped)
+	}
+}
+
+// writeWAVHeader пишет 44-байтовый заголовок PCM 16 бит стерео с размером данных size.
+func writeWAVHeader(f *os.File, rate beep.SampleRate, size int64) error {
+	h := make([]byte, 44)
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], uint32(36+size))
+	copy(h[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(h[22:], 2)
+	binary.LittleEndian.PutUint32(h[24:], uint32(rate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(rate)*4)
+	binary.LittleEndian.PutUint16(h[32:], 4)
+	binary.LittleEndian.PutUint16(h[34:], 16)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], uint32(size))
+	_, err := f.WriteAt(h, 0)
+	return err
+}
+
+func toInt16(v float64) int16 {
+	return int16(math.Round(max(-1, min(1, v)) * math.MaxInt16))
+}
+
+// clipFiles сохраняет клипы речи в том формате, в котором их отдал провайдер (mp3, wav, ogg...).
+type clipFiles struct {
+	dir    string
+	logger *zap.SugaredLogger
+	seq    atomic.Int64
+}
+
+// clipInfo — содержимое файла описания клипа.
+type clipInfo struct {
+	Time   time.Time `json:"time"`
+	Format string    `json:"format"`
+	Bytes  int64     `json:"bytes"`
+	GainDB float64   `json:"gain_db,omitempty"`
+	Meta
+}
+
+// record оборачивает поток клипа: прочитанные декодером байты пишутся в файл,
+// при закрытии рядом появляется описание <имя>.json. Если файл не создать — поток играет без записи.
+func (c *clipFiles) record(format string, gainDB float64, meta Meta, r io.ReadCloser) io.ReadCloser {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		c.logger.Warnw("Clip not recorded", "error", err)
+		return r
+	}
+	now := time.Now()
+	base := filepath.Join(c.dir, fmt.Sprintf("%s-%04d", now.Format("20060102-150405"), c.seq.Add(1)))
+	f, err := os.Create(base + "." + fileExt(format))
+	if err != nil {
+		c.logger.Warnw("Clip not recorded", "error", err)
+		return r
+	}
+	return &recording{ReadCloser: r, f: f, base: base, logger: c.logger,
+		info: clipInfo{Time: now, Format: strings.ToLower(format), GainDB: gainDB, Meta: meta}}
+}
+
+// fileExt — расширение файла клипа по формату провайдера.
+func fileExt(format string) string {
+	switch f := strings.ToLower(format); f {
+	case "oggopus", "ogg_opus", "opus", "oggvorbis", "ogg_vorbis", "vorbis", "oga":
+		return "ogg"
+	case "":
+		return "bin"
+	default:
+		return f
+	}
+}
+
+// recording — поток клипа, копирующий прочитанное в файл.
+type recording struct {
+	io.ReadCloser
+	f      *os.File
+	base   string
+	info   clipInfo
+	logger *zap.SugaredLogger
+	failed bool
+}
+
+func (r *recording) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 && !r.failed {
+		if _, werr := r.f.Write(p[:n]); werr != nil {
+			r.failed = true
+			r.logger.Warnw("Clip recording failed", "path", r.f.Name(), "error", werr)
+		}
+		r.info.Bytes += int64(n)
+	}
+	return n, err
+}
+
+func (r *recording) Close() error {
+	err := r.ReadCloser.Close()
+	if cerr := r.f.Close(); cerr != nil && !r.failed {
+		r.logger.Warnw("Clip recording failed", "path", r.f.Name(), "error", cerr)
+	}
+	data, _ := json.MarshalIndent(r.info, "", "  ")
+	if werr := os.WriteFile(r.base+".json", data, 0o644); werr != nil {
+		r.logger.Warnw("Clip description not written", "path", r.base+".json", "error", werr)
+	}
+	return err
+}
@@ -0,0 +1,12 @@
+// Package providers подключает все TTS-провайдеры: каждый регистрируется в реестре tts из своего init().
+// Достаточно импортировать пакет один раз (import _ ".../tts/providers").
+package providers
+
+import (
+	_ "OpenAIClient/internal/service/tts/gemini"
+	_ "OpenAIClient/internal/service/tts/google"
+	_ "OpenAIClient/internal/service/tts/httptts"
+	_ "OpenAIClient/internal/service/tts/local"
+	_ "OpenAIClient/internal/service/tts/openai"
+	_ "OpenAIClient/internal/service/tts/yandex"
+)
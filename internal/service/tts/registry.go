@@ -0,0 +1,136 @@
+package tts
+
+import (
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/tts/markup"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/v3"
+	"go.uber.org/zap"
+)
+
+// Deps — общие зависимости, которые могут понадобиться конструктору провайдера.
+type Deps struct {
+	OpenAI *openai.Client
+	Logger *zap.SugaredLogger
+}
+
+// Spec описывает провайдера для реестра. C — тип его блока настроек: свой тип пакета провайдера
+// (Key и Defaults — конфиг разбирает его по тегам env и yaml) или блок в config.Config (Settings).
+type Spec[C any] struct {
+	Name     string
+	Caps     Capabilities
+	Key      string                           // ключ блока настроек в YAML (openai_tts); поля C — с тегами env и yaml
+	Defaults C                                // значения по умолчанию для блока Key
+	Settings func(cfg *config.Config) C       // блок настроек из общего конфига, если Key не задан
+	New      func(c C, deps Deps) Synthesizer // конструктор; вызывается один раз при запуске
+	Validate func(c C) []string               // проблемы настроек для config check (опционально)
+	GainDB   func(c C) float64                // усиление речи в плеере, dB (опционально; Yandex — YC_TTS_VOLUME); только при AUDIO_LOUDNESS_ENABLED=false
+	Markup   func(c C) markup.Dialect         // разметка при этих настройках (опционально; иначе Caps.Markup)
+	Secrets  []string                         // переменные блока Key, скрываемые в config check
+}
+
+// Provider — созданный провайдер с его возможностями.
+type Provider struct {
+	Synthesizer
+	Name     string
+	Caps     Capabilities
+	Settings any     // блок настроек провайдера — входит в ключ кэша
+	GainDB   float64 // усиление речи в плеере, dB
+}
+
+// entry — провайдер в реестре со стёртым типом настроек.
+type entry struct {
+	caps     Capabilities
+	build    func(cfg *config.Config, deps Deps) Provider
+	validate func(cfg *config.Config) []string
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]entry{}
+)
+
+// Register добавляет провайдера в реестр; вызывается из init() пакета провайдера.
+func Register[C any](s Spec[C]) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := registry[s.Name]; dup {
+		panic("tts: provider registered twice: " + s.Name)
+	}
+	if s.Key != "" {
+		config.RegisterSection(s.Key, func() any { c := s.Defaults; return &c }, s.Secrets...)
+		s.Settings = func(cfg *config.Config) C {
+			if c, ok := cfg.Section(s.Key).(*C); ok {
+				return *c
+			}
+			return s.Defaults // конфиг собран до регистрации провайдера (тесты)
+		}
+	}
+	registry[s.Name] = entry{
+		caps: s.Caps,
+		build: func(cfg *config.Config, deps Deps) Provider {
+			c := s.Settings(cfg)
+			p := Provider{Synthesizer: s.New(c, deps), Name: s.Name, Caps: s.Caps, Settings: c}
+			// При выравнивании громкости все провайдеры звучат на одном уровне — своё усиление не применяется
+			if s.GainDB != nil && !cfg.AudioLoudness.Enabled {
+				p.GainDB = s.GainDB(c)
+			}
+			if s.Markup != nil {
+				p.Caps.Markup = s.Markup(c)
+			}
+			return p
+		},
+		validate: func(cfg *config.Config) []string {
+			if s.Validate == nil {
+				return nil
+			}
+			return s.Validate(s.Settings(cfg))
+		},
+	}
+}
+
+// Names возвращает имена зарегистрированных провайдеров по алфавиту.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Build создаёт провайдера по имени с его настройками из cfg.
+func Build(name string, cfg *config.Config, deps Deps) (Provider, error) {
+	mu.RLock()
+	e, ok := registry[name]
+	mu.RUnlock()
+	if !ok {
+		return Provider{}, fmt.Errorf("tts: unknown provider %q (%s)", name, strings.Join(Names(), "|"))
+	}
+	return e.build(cfg, deps), nil
+}
+
+// Validate проверяет цепочку провайдеров из cfg: имена и настройки каждого провайдера.
+func Validate(cfg *config.Config) error {
+	ve := &config.ValidationError{}
+	for _, name := range cfg.TTSChain() {
+		mu.RLock()
+		e, ok := registry[name]
+		mu.RUnlock()
+		if !ok {
+			ve.Problems = append(ve.Problems, fmt.Sprintf("TTS_PROVIDERS/TTS_SERVICE: unknown provider %q (%s)", name, strings.Join(Names(), "|")))
+			continue
+		}
+		ve.Problems = append(ve.Problems, e.validate(cfg)...)
+	}
+	if len(ve.Problems) == 0 {
+		return nil
+	}
+	return ve
+}
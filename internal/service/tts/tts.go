@@ -1,14 +1,40 @@
 package tts
 
 import (
+	"OpenAIClient/internal/service/tts/markup"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 )
 
 // Synthesizer абстракция TTS. Метод выполняет синтез и возвращает поток аудио и его формат,
-// а воспроизведение выполняется вызывающей стороной.
-// cfg — провайдер-специфичная конфигурация (например, config.YandexTTSConfig).
-// prompt — опциональный системный промпт для провайдера (используется только Gemini; для остальных пустой).
+// а воспроизведение выполняется вызывающей стороной. Настройки голоса провайдер получает при создании (см. Register).
+// prompt — опциональный промпт стиля (текст персонажа); передаётся только провайдерам с Capabilities.StylePrompt.
 type Synthesizer interface {
-	Synthesize(ctx context.Context, text string, prompt string, cfg any) (format string, rc io.ReadCloser, err error)
+	Synthesize(ctx context.Context, text string, prompt string) (format string, rc io.ReadCloser, err error)
+}
+
+// Capabilities — возможности провайдера: от них зависят разметка, промпт и нарезка текста.
+type Capabilities struct {
+	Formats      []string       // форматы аудио, которые может отдавать провайдер
+	Markup       markup.Dialect // разметка речи (паузы, выделение, шёпот, темп), которую понимает провайдер
+	StylePrompt  bool           // принимает промпт стиля (текст персонажа)
+	PlusStress   bool           // понимает ударение "+" перед гласной; иначе словарь ставит знак ударения U+0301
+	MaxTextLen   int            // предел длины текста в символах (после перевода разметки); 0 — без предела
+	MaxTextBytes int            // предел длины текста в байтах UTF-8 (после перевода разметки); 0 — без предела
+}
+
+// ErrInvalidInput — провайдер отверг сам запрос (слишком длинный текст, неверная разметка), а не упал.
+// Цепочка передаёт такой текст следующему провайдеру, не ставя этот на cooldown.
+var ErrInvalidInput = errors.New("invalid input")
+
+// StatusError помечает ошибку HTTP-ответа провайдера: 400, 413 и 422 — ErrInvalidInput, остальные статусы — как есть.
+func StatusError(status int, err error) error {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	return err
 }
@@ -2,6 +2,8 @@ package yandex
 
 import (
 	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/metrics"
+	"OpenAIClient/internal/service/tts"
 	"bytes"
 	"context"
 	"errors"
@@ -10,6 +12,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 const endpoint = "https://tts.api.cloud.yandex.net/speech/v1/tts:synthesize"
@@ -17,18 +20,24 @@ const endpoint = "https://tts.api.cloud.yandex.net/speech/v1/tts:synthesize"
 // Client реализует синтез речи через Yandex SpeechKit.
 type Client struct {
 	http *http.Client
+	cfg  config.YandexTTSConfig
 }
 
-func New() *Client {
-	return &Client{http: http.DefaultClient}
+func New(cfg config.YandexTTSConfig) *Client {
+	return &Client{http: http.DefaultClient, cfg: cfg}
 }
 
-// Synthesize выполняет запрос к Yandex TTS и возвращает аудио. cfg должен быть config.YandexTTSConfig.
-func (c *Client) Synthesize(ctx context.Context, text string, _ string, cfg any) (string, io.ReadCloser, error) {
-	yc, ok := cfg.(config.YandexTTSConfig)
-	if !ok {
-		return "", nil, errors.New("yandex tts: unexpected config type")
-	}
+// Synthesize выполняет запрос к Yandex TTS и возвращает аудио.
+func (c *Client) Synthesize(ctx context.Context, text string, prompt string) (string, io.ReadCloser, error) {
+	start := time.Now()
+	format, rc, err := c.synthesize(ctx, text, prompt)
+	metrics.ObserveTTS("yandex", time.Since(start), err)
+	return format, rc, err
+}
+
+// synthesize — сам запрос к провайдеру; Synthesize оборачивает его метриками.
+func (c *Client) synthesize(ctx context.Context, text string, _ string) (string, io.ReadCloser, error) {
+	yc := c.cfg
 	if strings.TrimSpace(yc.APIKey) == "" {
 		return "", nil, errors.New("yandex tts: empty API key (set YC_TTS_API_KEY in .env/ENV or pass via flag)")
 	}
@@ -66,7 +75,7 @@ func (c *Client) Synthesize(ctx context.Context, text string, _ string, cfg any)
 		}
 		// Закрыть тело при ошибке
 		resp.Body.Close()
-		return "", nil, fmt.Errorf("yandex tts error: status=%d, body=%s", resp.StatusCode, bytes.TrimSpace(b))
+		return "", nil, tts.StatusError(resp.StatusCode, fmt.Errorf("yandex tts error: status=%d, body=%s", resp.StatusCode, bytes.TrimSpace(b)))
 	}
 
 	return format, resp.Body, nil
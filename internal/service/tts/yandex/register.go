@@ -0,0 +1,37 @@
+package yandex
+
+import (
+	"OpenAIClient/internal/config"
+	"OpenAIClient/internal/service/tts"
+	"OpenAIClient/internal/service/tts/markup"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	tts.Register(tts.Spec[config.YandexTTSConfig]{
+		Name:     "yandex",
+		Caps:     tts.Capabilities{Formats: []string{"mp3", "wav", "oggopus"}, Markup: markup.Yandex, PlusStress: true, MaxTextLen: 5000},
+		Settings: func(cfg *config.Config) config.YandexTTSConfig { return cfg.YandexTTS },
+		New: func(c config.YandexTTSConfig, _ tts.Deps) tts.Synthesizer {
+			return New(c)
+		},
+		Validate: func(c config.YandexTTSConfig) []string {
+			var problems []string
+			if strings.TrimSpace(c.APIKey) == "" {
+				problems = append(problems, "YC_TTS_API_KEY: required for TTS provider yandex")
+			}
+			switch strings.ToLower(strings.TrimSpace(c.Format)) {
+			case "mp3", "wav", "oggopus":
+			default:
+				problems = append(problems, fmt.Sprintf("YC_TTS_FORMAT: unsupported value %q (mp3|wav|oggopus)", c.Format))
+			}
+			return problems
+		},
+		// API громкость не регулирует — учитываем YC_TTS_VOLUME усилением в плеере (без выравнивания громкости)
+		GainDB: func(c config.YandexTTSConfig) float64 {
+			v := max(0, min(100, c.Volume))
+			return float64(v-100) / 5.0
+		},
+	})
+}
@@ -69,7 +69,7 @@ func New(cfg config.VTubeConfig, token string, log *zap.SugaredLogger) *Client {
 // Start выполняет подключение и авто‑переподключение до остановки контекста.
 func (c *Client) Start(ctx context.Context) error {
 	// Он‑деманд режим: на старте лишь единожды загружаем хоткеи и кэшируем их локально.
-	// При неудаче — возвращаем ошибку (основное приложение завершится фатально по требованиям).
+	// При неудаче — возвращаем ошибку; повтор с backoff выполняет супервизор приложения.
 	tctx, cancel := context.WithTimeoutCause(ctx, 10*time.Second, errors.New("vtube: initial connect timeout"))
 	defer cancel()
 	if err := c.loadHotkeys(tctx); err != nil {
@@ -208,9 +208,11 @@ func (c *Client) close() error { // историческая функция; в
 func (c *Client) TriggerByNames(names []string) error {
 	// копируем карту хоткеев чтобы уменьшить окно блокировки
 	m := map[string]string{}
+	c.mu.RLock()
 	for k, v := range c.byName {
 		m[k] = v
 	}
+	c.mu.RUnlock()
 
 	// упорядочим имена стабильно
 	names = slices.Compact(names)
@@ -0,0 +1,158 @@
+package vtube
+
+import (
+	"OpenAIClient/internal/config"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// EnvelopeSource — источник огибающей звучащей речи (микшер плеера).
+type EnvelopeSource interface {
+	SpeechEnvelope() (levelDB, brightness float64, speaking bool)
+}
+
+// Диапазон уровня речи, который раскрывает рот от закрытого до полностью открытого.
+const (
+	lipSyncFloorDB = -45.0
+	lipSyncRangeDB = 30.0
+)
+
+type paramValue struct {
+	ID    string  `json:"id"`
+	Value float64 `json:"value"`
+}
+
+type injectReq struct {
+	FaceFound       bool         `json:"faceFound"`
+	Mode            string       `json:"mode"`
+	ParameterValues []paramValue `json:"parameterValues"`
+}
+
+// LipSync двигает рот аватара по речи, которая сейчас звучит: InjectParameterDataRequest по постоянному
+// соединению с частотой FPS. Пока речи нет, параметры не отправляются, и рот снова ведёт трекинг VTS.
+type LipSync struct {
+	c   *Client
+	src EnvelopeSource
+	cfg config.LipSyncConfig
+	log *zap.SugaredLogger
+
+	open, smile float64 // сглаженные значения параметров
+}
+
+func NewLipSync(c *Client, src EnvelopeSource, cfg config.LipSyncConfig, log *zap.SugaredLogger) *LipSync {
+	return &LipSync{c: c, src: src, cfg: cfg, log: log}
+}
+
+// Run держит соединение с VTS до остановки контекста; при обрыве возвращает ошибку — переподключает супервизор.
+func (l *LipSync) Run(ctx context.Context) error {
+	conn, err := l.c.dialAndAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("vtube lipsync: %w", err)
+	}
+	defer conn.Close()
+	if l.log != nil {
+		l.log.Infow("VTS lip-sync connected", "fps", l.cfg.FPS)
+	}
+
+	// Ответы на инъекции не нужны, но их надо вычитывать; ошибки API (например, неизвестный параметр) — в лог
+	readErr := make(chan error, 1)
+	go func() {
+		_ = conn.SetReadDeadline(time.Time{})
+		for {
+			var raw struct {
+				MessageType string          `json:"messageType"`
+				Data        json.RawMessage `json:"data"`
+			}
+			if err := conn.ReadJSON(&raw); err != nil {
+				readErr <- err
+				return
+			}
+			if raw.MessageType == "APIError" && l.log != nil {
+				var ae apiError
+				_ = json.Unmarshal(raw.Data, &ae)
+				l.log.Warnw("VTS lip-sync parameter rejected", "error_id", ae.ErrorID, "message", ae.Message)
+			}
+		}
+	}()
+
+	t := time.NewTicker(time.Second / time.Duration(l.cfg.FPS))
+	defer t.Stop()
+	active := false
+	for {
+		select {
+		case <-ctx.Done():
+			if active {
+				_ = l.send(conn, 0, 0) // не оставляем рот открытым
+			}
+			return nil
+		case err := <-readErr:
+			return fmt.Errorf("vtube lipsync: %w", err)
+		case <-t.C:
+		}
+		db, brightness, speaking := l.src.SpeechEnvelope()
+		if !speaking {
+			if active {
+				// Закрываем рот один раз и замолкаем: через секунду без инъекций VTS вернёт параметры трекингу
+				l.open, l.smile, active = 0, 0, false
+				if err := l.send(conn, 0, 0); err != nil {
+					return fmt.Errorf("vtube lipsync: %w", err)
+				}
+			}
+			continue
+		}
+		active = true
+		l.step(db, brightness)
+		if err := l.send(conn, l.open, l.smile); err != nil {
+			return fmt.Errorf("vtube lipsync: %w", err)
+		}
+	}
+}
+
+// step переводит огибающую в параметры рта: открытие — по громкости, улыбка — по яркости («и», «с»).
+// Рот открывается быстро и закрывается плавнее, чтобы не дрожать на каждом слоге.
+func (l *LipSync) step(db, brightness float64) {
+	open := 0.0
+	if !math.IsInf(db, -1) {
+		open = clamp01((db - lipSyncFloorDB) / lipSyncRangeDB * l.cfg.Gain)
+	}
+	smile := clamp01((brightness - 0.02) / 0.15)
+	if open < 0.05 {
+		smile = l.smile // в паузах форма рта не меняется
+	}
+	l.open = follow(l.open, open)
+	l.smile += (smile - l.smile) * 0.3
+}
+
+// follow: атака почти мгновенная, затухание — примерно за 3-4 кадра.
+func follow(cur, target float64) float64 {
+	if target > cur {
+		return cur + (target-cur)*0.8
+	}
+	return cur + (target-cur)*0.35
+}
+
+func clamp01(v float64) float64 { return max(0, min(1, v)) }
+
+func (l *LipSync) send(conn *websocket.Conn, open, smile float64) error {
+	values := []paramValue{{ID: l.cfg.OpenParam, Value: open}}
+	if p := strings.TrimSpace(l.cfg.SmileParam); p != "" {
+		values = append(values, paramValue{ID: p, Value: smile})
+	}
+	req := envelope[injectReq]{
+		APIName:     "VTubeStudioPublicAPI",
+		APIVersion:  l.c.cfg.APIVersion,
+		RequestID:   fmt.Sprintf("lip-%d", time.Now().UnixNano()),
+		MessageType: "InjectParameterDataRequest",
+		// faceFound=false: VTS не считает лицо найденным из-за плагина; mode=set — значения заменяют трекинг
+		Data: injectReq{FaceFound: false, Mode: "set", ParameterValues: values},
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	return conn.WriteJSON(req)
+}